@@ -0,0 +1,35 @@
+package altsrc
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// findConfigFile returns the path of the first of names found in dirs,
+// searching the dirs in order. Environment variables in dirs are expanded
+// and dirs that refer to an unset variable are skipped. It returns an empty
+// string when no file is found.
+func findConfigFile(names []string, dirs []string) string {
+	for _, dir := range dirs {
+		unset := false
+		dir = os.Expand(dir, func(key string) string {
+			val, ok := os.LookupEnv(key)
+			if !ok || val == "" {
+				unset = true
+			}
+			return val
+		})
+		if unset {
+			continue
+		}
+
+		for _, name := range names {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,94 @@
+package altsrc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TestFindConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "urfave_cli_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cwd := filepath.Join(dir, "cwd")
+	xdg := filepath.Join(dir, "xdg")
+	etc := filepath.Join(dir, "etc")
+	for _, d := range []string{cwd, filepath.Join(xdg, "app"), etc} {
+		_ = os.MkdirAll(d, 0755)
+	}
+	_ = ioutil.WriteFile(filepath.Join(xdg, "app", "config.yaml"), []byte("name: xdg"), 0644)
+	_ = ioutil.WriteFile(filepath.Join(etc, "config.yaml"), []byte("name: etc"), 0644)
+	_ = ioutil.WriteFile(filepath.Join(etc, "config.yml"), []byte("name: etc"), 0644)
+
+	defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+	_ = os.Unsetenv("XDG_CONFIG_HOME")
+
+	dirs := []string{cwd, "$XDG_CONFIG_HOME/app", etc}
+	names := []string{"config.yaml", "config.yml"}
+
+	expect(t, findConfigFile(names, dirs), filepath.Join(etc, "config.yaml"))
+
+	_ = os.Setenv("XDG_CONFIG_HOME", xdg)
+	expect(t, findConfigFile(names, dirs), filepath.Join(xdg, "app", "config.yaml"))
+
+	_ = ioutil.WriteFile(filepath.Join(cwd, "config.yml"), []byte("name: cwd"), 0644)
+	expect(t, findConfigFile(names, dirs), filepath.Join(cwd, "config.yml"))
+
+	expect(t, findConfigFile(names, []string{filepath.Join(dir, "missing")}), "")
+}
+
+func TestNewYamlSourceFromSearchPaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "urfave_cli_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	user := filepath.Join(dir, "user")
+	system := filepath.Join(dir, "system")
+	_ = os.MkdirAll(user, 0755)
+	_ = os.MkdirAll(system, 0755)
+	_ = ioutil.WriteFile(filepath.Join(user, "config.yaml"), []byte("name: user\nnested:\n  count: 3"), 0644)
+	_ = ioutil.WriteFile(filepath.Join(system, "config.yaml"), []byte("name: system\nport: 80"), 0644)
+
+	run := func(dirs []string, args ...string) (name string, count, port int) {
+		flags := []cli.Flag{
+			NewStringFlag(&cli.StringFlag{Name: "name", Value: "default"}),
+			NewIntFlag(&cli.IntFlag{Name: "nested.count"}),
+			NewIntFlag(&cli.IntFlag{Name: "port"}),
+		}
+		app := &cli.App{
+			Flags:  flags,
+			Before: InitInputSourceWithContext(flags, NewYamlSourceFromSearchPaths([]string{"config.yaml"}, dirs)),
+			Action: func(c *cli.Context) error {
+				name = c.String("name")
+				count = c.Int("nested.count")
+				port = c.Int("port")
+				return nil
+			},
+		}
+		expect(t, app.Run(append([]string{"run"}, args...)), nil)
+		return
+	}
+
+	name, count, port := run([]string{filepath.Join(dir, "missing"), user, system})
+	expect(t, name, "user")
+	expect(t, count, 3)
+	expect(t, port, 0)
+
+	name, _, port = run([]string{system, user})
+	expect(t, name, "system")
+	expect(t, port, 80)
+
+	name, _, _ = run([]string{user}, "--name", "flag")
+	expect(t, name, "flag")
+
+	name, _, _ = run([]string{filepath.Join(dir, "missing")})
+	expect(t, name, "default")
+}
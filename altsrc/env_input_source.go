@@ -0,0 +1,145 @@
+package altsrc
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// envInputSource implements InputSourceContext to return data from
+// environment variables named after the flags.
+type envInputSource struct {
+	prefix string
+}
+
+// NewEnvInputSource creates an InputSourceContext that reads the value of
+// each flag from an environment variable named after it. The flag name is
+// upper cased, '-' and '.' are replaced with '_' and prefix is prepended, so
+// that with the prefix "APP_" the flag "log-level" is read from
+// APP_LOG_LEVEL. Slice values are split on cli.EnvSliceDelimiter.
+func NewEnvInputSource(prefix string) InputSourceContext {
+	return &envInputSource{prefix: prefix}
+}
+
+// envVarName returns the name of the environment variable read for name
+func (es *envInputSource) envVarName(name string) string {
+	return es.prefix + strings.NewReplacer("-", "_", ".", "_").Replace(strings.ToUpper(name))
+}
+
+func (es *envInputSource) lookup(name string) (string, bool) {
+	return os.LookupEnv(es.envVarName(name))
+}
+
+// isSet reports whether the environment variable for name is set
+func (es *envInputSource) isSet(name string) bool {
+	_, exists := es.lookup(name)
+	return exists
+}
+
+// Source returns the prefix of the environment variables
+func (es *envInputSource) Source() string {
+	return "env:" + es.prefix
+}
+
+// Int returns an int from the environment if it is set otherwise returns 0
+func (es *envInputSource) Int(name string) (int, error) {
+	if value, exists := es.lookup(name); exists {
+		return castInt(name, value)
+	}
+	return 0, nil
+}
+
+// Duration returns a duration from the environment if it is set otherwise
+// returns 0
+func (es *envInputSource) Duration(name string) (time.Duration, error) {
+	if value, exists := es.lookup(name); exists {
+		return castDuration(name, value)
+	}
+	return 0, nil
+}
+
+// Float64 returns a float64 from the environment if it is set otherwise
+// returns 0
+func (es *envInputSource) Float64(name string) (float64, error) {
+	if value, exists := es.lookup(name); exists {
+		return castFloat64(name, value)
+	}
+	return 0, nil
+}
+
+// String returns a string from the environment if it is set otherwise
+// returns an empty string
+func (es *envInputSource) String(name string) (string, error) {
+	value, _ := es.lookup(name)
+	return value, nil
+}
+
+// StringSlice returns a []string from the environment if it is set otherwise
+// returns nil
+func (es *envInputSource) StringSlice(name string) ([]string, error) {
+	value, exists := es.lookup(name)
+	if !exists {
+		return nil, nil
+	}
+
+	stringSlice := []string{}
+	for _, s := range strings.Split(value, cli.EnvSliceDelimiter) {
+		if s = strings.TrimSpace(s); s != "" {
+			stringSlice = append(stringSlice, s)
+		}
+	}
+	return stringSlice, nil
+}
+
+// IntSlice returns an []int from the environment if it is set otherwise
+// returns nil
+func (es *envInputSource) IntSlice(name string) ([]int, error) {
+	stringSlice, err := es.StringSlice(name)
+	if stringSlice == nil || err != nil {
+		return nil, err
+	}
+
+	intSlice := make([]int, 0, len(stringSlice))
+	for _, s := range stringSlice {
+		intValue, err := castInt(name, s)
+		if err != nil {
+			return nil, err
+		}
+		intSlice = append(intSlice, intValue)
+	}
+	return intSlice, nil
+}
+
+// Generic returns a cli.Generic holding the string from the environment if
+// it is set otherwise returns nil
+func (es *envInputSource) Generic(name string) (cli.Generic, error) {
+	value, exists := es.lookup(name)
+	if !exists {
+		return nil, nil
+	}
+	generic := envGeneric(value)
+	return &generic, nil
+}
+
+// Bool returns a bool from the environment if it is set otherwise returns
+// false
+func (es *envInputSource) Bool(name string) (bool, error) {
+	if value, exists := es.lookup(name); exists {
+		return castBool(name, value)
+	}
+	return false, nil
+}
+
+// envGeneric is the cli.Generic returned for a value from the environment
+type envGeneric string
+
+func (g *envGeneric) Set(value string) error {
+	*g = envGeneric(value)
+	return nil
+}
+
+func (g *envGeneric) String() string {
+	return string(*g)
+}
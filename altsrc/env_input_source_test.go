@@ -0,0 +1,127 @@
+package altsrc
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// setTestEnv sets env and returns a func that unsets it again
+func setTestEnv(env map[string]string) func() {
+	for k, v := range env {
+		_ = os.Setenv(k, v)
+	}
+	return func() {
+		for k := range env {
+			_ = os.Unsetenv(k)
+		}
+	}
+}
+
+func TestEnvInputSourceTypes(t *testing.T) {
+	defer setTestEnv(map[string]string{
+		"APP_WORKERS":   "4",
+		"APP_TIMEOUT":   "1m30s",
+		"APP_RATIO":     "0.25",
+		"APP_NAME":      "worker",
+		"APP_TAGS":      "a, b,,c",
+		"APP_PORTS":     "80,443",
+		"APP_LOG_LEVEL": "debug",
+		"APP_DB_HOST":   "db.example.com",
+		"APP_VERBOSE":   "true",
+	})()
+	src := NewEnvInputSource("APP_")
+
+	workers, err := src.Int("workers")
+	expect(t, err, nil)
+	expect(t, workers, 4)
+
+	timeout, err := src.Duration("timeout")
+	expect(t, err, nil)
+	expect(t, timeout, 90*time.Second)
+
+	ratio, err := src.Float64("ratio")
+	expect(t, err, nil)
+	expect(t, ratio, 0.25)
+
+	name, err := src.String("name")
+	expect(t, err, nil)
+	expect(t, name, "worker")
+
+	tags, err := src.StringSlice("tags")
+	expect(t, err, nil)
+	expect(t, tags, []string{"a", "b", "c"})
+
+	ports, err := src.IntSlice("ports")
+	expect(t, err, nil)
+	expect(t, ports, []int{80, 443})
+
+	level, err := src.Generic("log-level")
+	expect(t, err, nil)
+	expect(t, level.String(), "debug")
+
+	host, err := src.String("db.host")
+	expect(t, err, nil)
+	expect(t, host, "db.example.com")
+
+	verbose, err := src.Bool("verbose")
+	expect(t, err, nil)
+	expect(t, verbose, true)
+}
+
+func TestEnvInputSourceUnset(t *testing.T) {
+	src := NewEnvInputSource("ALTSRC_TEST_UNSET_")
+
+	workers, err := src.Int("workers")
+	expect(t, err, nil)
+	expect(t, workers, 0)
+
+	tags, err := src.StringSlice("tags")
+	expect(t, err, nil)
+	expect(t, tags, []string(nil))
+
+	level, err := src.Generic("level")
+	expect(t, err, nil)
+	expect(t, level, nil)
+}
+
+func TestEnvInputSourceTypeErrors(t *testing.T) {
+	defer setTestEnv(map[string]string{
+		"WORKERS": "many",
+		"TIMEOUT": "soon",
+		"RATIO":   "half",
+		"PORTS":   "80,http",
+		"VERBOSE": "sometimes",
+	})()
+	src := NewEnvInputSource("")
+
+	_, err := src.Int("workers")
+	refute(t, nil, err)
+	_, err = src.Duration("timeout")
+	refute(t, nil, err)
+	_, err = src.Float64("ratio")
+	refute(t, nil, err)
+	_, err = src.IntSlice("ports")
+	refute(t, nil, err)
+	_, err = src.Bool("verbose")
+	refute(t, nil, err)
+}
+
+func TestEnvInputSourceLayered(t *testing.T) {
+	defer setTestEnv(map[string]string{"APP_WORKERS": "8"})()
+	src := NewLayeredSource(
+		NewMapInputSource("config.yaml", map[interface{}]interface{}{
+			"workers": 2,
+			"name":    "config",
+		}),
+		NewEnvInputSource("APP_"),
+	)
+
+	workers, err := src.Int("workers")
+	expect(t, err, nil)
+	expect(t, workers, 8)
+
+	name, err := src.String("name")
+	expect(t, err, nil)
+	expect(t, name, "config")
+}
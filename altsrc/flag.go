@@ -74,6 +74,7 @@ func (f *GenericFlag) ApplyInputSourceValue(context *cli.Context, isc InputSourc
 				for _, name := range f.Names() {
 					_ = f.set.Set(name, value.String())
 				}
+				f.GenericFlag.HasBeenSet = true
 			}
 		}
 	}
@@ -97,6 +98,7 @@ func (f *StringSliceFlag) ApplyInputSourceValue(context *cli.Context, isc InputS
 						underlyingFlag.Value = &sliceValue
 					}
 				}
+				f.StringSliceFlag.HasBeenSet = true
 			}
 		}
 	}
@@ -119,6 +121,7 @@ func (f *IntSliceFlag) ApplyInputSourceValue(context *cli.Context, isc InputSour
 						underlyingFlag.Value = &sliceValue
 					}
 				}
+				f.IntSliceFlag.HasBeenSet = true
 			}
 		}
 	}
@@ -133,10 +136,13 @@ func (f *BoolFlag) ApplyInputSourceValue(context *cli.Context, isc InputSourceCo
 			if err != nil {
 				return err
 			}
-			if value {
+			// false is applied as well when the source can tell it is set,
+			// so that it overrides a flag with a true Value
+			if value || hasSourceValue(isc, f.BoolFlag.Name) {
 				for _, name := range f.Names() {
 					_ = f.set.Set(name, strconv.FormatBool(value))
 				}
+				f.BoolFlag.HasBeenSet = true
 			}
 		}
 	}
@@ -155,6 +161,7 @@ func (f *StringFlag) ApplyInputSourceValue(context *cli.Context, isc InputSource
 				for _, name := range f.Names() {
 					_ = f.set.Set(name, value)
 				}
+				f.StringFlag.HasBeenSet = true
 			}
 		}
 	}
@@ -183,6 +190,7 @@ func (f *PathFlag) ApplyInputSourceValue(context *cli.Context, isc InputSourceCo
 
 					_ = f.set.Set(name, value)
 				}
+				f.PathFlag.HasBeenSet = true
 			}
 		}
 	}
@@ -201,6 +209,7 @@ func (f *IntFlag) ApplyInputSourceValue(context *cli.Context, isc InputSourceCon
 				for _, name := range f.Names() {
 					_ = f.set.Set(name, strconv.FormatInt(int64(value), 10))
 				}
+				f.IntFlag.HasBeenSet = true
 			}
 		}
 	}
@@ -219,6 +228,7 @@ func (f *DurationFlag) ApplyInputSourceValue(context *cli.Context, isc InputSour
 				for _, name := range f.Names() {
 					_ = f.set.Set(name, value.String())
 				}
+				f.DurationFlag.HasBeenSet = true
 			}
 		}
 	}
@@ -238,12 +248,23 @@ func (f *Float64Flag) ApplyInputSourceValue(context *cli.Context, isc InputSourc
 				for _, name := range f.Names() {
 					_ = f.set.Set(name, floatStr)
 				}
+				f.Float64Flag.HasBeenSet = true
 			}
 		}
 	}
 	return nil
 }
 
+// hasSourceValue reports whether isc can tell that it has a value for name
+// which is not explicitly unset
+func hasSourceValue(isc InputSourceContext, name string) bool {
+	if msc, ok := isc.(*MapInputSource); ok && msc.IsUnset(name) {
+		return false
+	}
+	kc, ok := isc.(keyChecker)
+	return ok && kc.isSet(name)
+}
+
 func isEnvVarSet(envVars []string) bool {
 	for _, envVar := range envVars {
 		if _, ok := syscall.Getenv(envVar); ok {
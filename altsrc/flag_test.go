@@ -315,6 +315,42 @@ func TestFloat64ApplyInputSourceMethodEnvVarSet(t *testing.T) {
 	expect(t, 1.4, c.Float64("test"))
 }
 
+func TestBoolApplyInputSourceOnlyFromSource(t *testing.T) {
+	verbose := NewBoolFlag(&cli.BoolFlag{Name: "verbose", Aliases: []string{"v"}})
+	color := NewBoolFlag(&cli.BoolFlag{Name: "color", Value: true})
+	debug := NewBoolFlag(&cli.BoolFlag{Name: "debug"})
+	flags := []cli.Flag{verbose, color, debug}
+
+	ran := false
+	app := &cli.App{
+		Flags: flags,
+		Before: InitInputSource(flags, func() (InputSourceContext, error) {
+			return NewMapInputSource("config.yaml", map[interface{}]interface{}{
+				"verbose": true,
+				"color":   false,
+			}), nil
+		}),
+		Action: func(c *cli.Context) error {
+			ran = true
+			expect(t, c.Bool("verbose"), true)
+			expect(t, c.Bool("v"), true)
+			expect(t, c.IsSet("verbose"), true)
+			expect(t, c.Bool("color"), false)
+			expect(t, c.IsSet("color"), true)
+			expect(t, c.Bool("debug"), false)
+			expect(t, c.IsSet("debug"), false)
+			return nil
+		},
+	}
+
+	err := app.Run([]string{"app"})
+	expect(t, err, nil)
+	expect(t, ran, true)
+	expect(t, verbose.IsSet(), true)
+	expect(t, color.IsSet(), true)
+	expect(t, debug.IsSet(), false)
+}
+
 func runTest(t *testing.T, test testApplyInputSource) *cli.Context {
 	inputSource := &MapInputSource{
 		file:     test.SourcePath,
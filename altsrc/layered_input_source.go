@@ -0,0 +1,156 @@
+package altsrc
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// keyChecker is implemented by input sources that can tell a key that is
+// absent apart from a key that is present with its zero value.
+type keyChecker interface {
+	isSet(name string) bool
+}
+
+// layeredSource implements InputSourceContext on top of an ordered list
+// of other input sources.
+type layeredSource struct {
+	sources []InputSourceContext
+}
+
+// NewLayeredSource creates an InputSourceContext that looks up each key in
+// the given sources. Later sources take precedence over earlier ones, so the
+// last source that has a key set wins, e.g. a system-wide config followed by
+// a user config lets the user config override individual keys.
+//
+// Sources that cannot report whether a key is present treat it as set when
+// the lookup returns an error or a non-zero value.
+func NewLayeredSource(sources ...InputSourceContext) InputSourceContext {
+	return &layeredSource{sources: sources}
+}
+
+// Source returns the source of the layer with the highest precedence
+func (ls *layeredSource) Source() string {
+	if len(ls.sources) == 0 {
+		return ""
+	}
+	return ls.sources[len(ls.sources)-1].Source()
+}
+
+// lookup calls get on the last source that has name set. It reports false
+// when none of the sources has name set.
+func (ls *layeredSource) lookup(name string, get func(InputSourceContext) (interface{}, error)) (interface{}, bool, error) {
+	for i := len(ls.sources) - 1; i >= 0; i-- {
+		src := ls.sources[i]
+		if kc, ok := src.(keyChecker); ok {
+			if !kc.isSet(name) {
+				continue
+			}
+			v, err := get(src)
+			return v, true, err
+		}
+
+		v, err := get(src)
+		if err != nil || (v != nil && !reflect.ValueOf(v).IsZero()) {
+			return v, true, err
+		}
+	}
+	return nil, false, nil
+}
+
+// Int returns an int from the layer with the highest precedence that has it
+// set, otherwise returns 0
+func (ls *layeredSource) Int(name string) (int, error) {
+	v, ok, err := ls.lookup(name, func(src InputSourceContext) (interface{}, error) {
+		return src.Int(name)
+	})
+	if !ok || err != nil {
+		return 0, err
+	}
+	return v.(int), nil
+}
+
+// Duration returns a duration from the layer with the highest precedence
+// that has it set, otherwise returns 0
+func (ls *layeredSource) Duration(name string) (time.Duration, error) {
+	v, ok, err := ls.lookup(name, func(src InputSourceContext) (interface{}, error) {
+		return src.Duration(name)
+	})
+	if !ok || err != nil {
+		return 0, err
+	}
+	return v.(time.Duration), nil
+}
+
+// Float64 returns a float64 from the layer with the highest precedence that
+// has it set, otherwise returns 0
+func (ls *layeredSource) Float64(name string) (float64, error) {
+	v, ok, err := ls.lookup(name, func(src InputSourceContext) (interface{}, error) {
+		return src.Float64(name)
+	})
+	if !ok || err != nil {
+		return 0, err
+	}
+	return v.(float64), nil
+}
+
+// String returns a string from the layer with the highest precedence that
+// has it set, otherwise returns ""
+func (ls *layeredSource) String(name string) (string, error) {
+	v, ok, err := ls.lookup(name, func(src InputSourceContext) (interface{}, error) {
+		return src.String(name)
+	})
+	if !ok || err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+// StringSlice returns a []string from the layer with the highest precedence
+// that has it set, otherwise returns nil
+func (ls *layeredSource) StringSlice(name string) ([]string, error) {
+	v, ok, err := ls.lookup(name, func(src InputSourceContext) (interface{}, error) {
+		return src.StringSlice(name)
+	})
+	if !ok || err != nil {
+		return nil, err
+	}
+	return v.([]string), nil
+}
+
+// IntSlice returns an []int from the layer with the highest precedence that
+// has it set, otherwise returns nil
+func (ls *layeredSource) IntSlice(name string) ([]int, error) {
+	v, ok, err := ls.lookup(name, func(src InputSourceContext) (interface{}, error) {
+		return src.IntSlice(name)
+	})
+	if !ok || err != nil {
+		return nil, err
+	}
+	return v.([]int), nil
+}
+
+// Generic returns a cli.Generic from the layer with the highest precedence
+// that has it set, otherwise returns nil
+func (ls *layeredSource) Generic(name string) (cli.Generic, error) {
+	v, ok, err := ls.lookup(name, func(src InputSourceContext) (interface{}, error) {
+		return src.Generic(name)
+	})
+	if !ok || err != nil || v == nil {
+		return nil, err
+	}
+	return v.(cli.Generic), nil
+}
+
+// Bool returns a bool from the layer with the highest precedence that has it
+// set, otherwise returns false
+func (ls *layeredSource) Bool(name string) (bool, error) {
+	v, ok, err := ls.lookup(name, func(src InputSourceContext) (interface{}, error) {
+		return src.Bool(name)
+	})
+	if !ok || err != nil {
+		return false, err
+	}
+	return v.(bool), nil
+}
@@ -0,0 +1,93 @@
+package altsrc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLayeredSourceLastWins(t *testing.T) {
+	system := NewMapInputSource("system.yaml", map[interface{}]interface{}{
+		"timeout": time.Minute,
+		"verbose": true,
+		"name":    "system",
+		"db": map[interface{}]interface{}{
+			"port": 5432,
+		},
+	})
+	user := NewMapInputSource("user.yaml", map[interface{}]interface{}{
+		"verbose": false,
+		"name":    "user",
+	})
+	src := NewLayeredSource(system, user)
+
+	expect(t, src.Source(), "user.yaml")
+
+	name, err := src.String("name")
+	expect(t, err, nil)
+	expect(t, name, "user")
+
+	// a zero value in the second layer still overrides the first
+	verbose, err := src.Bool("verbose")
+	expect(t, err, nil)
+	expect(t, verbose, false)
+
+	timeout, err := src.Duration("timeout")
+	expect(t, err, nil)
+	expect(t, timeout, time.Minute)
+
+	port, err := src.Int("db.port")
+	expect(t, err, nil)
+	expect(t, port, 5432)
+
+	missing, err := src.String("missing")
+	expect(t, err, nil)
+	expect(t, missing, "")
+}
+
+func TestLayeredSourceWithJSON(t *testing.T) {
+	base := NewMapInputSource("base.yaml", map[interface{}]interface{}{
+		"retries": 3,
+		"tags":    []interface{}{"a"},
+	})
+	override, err := NewJSONSource([]byte(`{"retries": 0}`))
+	expect(t, err, nil)
+	src := NewLayeredSource(base, override)
+
+	retries, err := src.Int("retries")
+	expect(t, err, nil)
+	expect(t, retries, 0)
+
+	tags, err := src.StringSlice("tags")
+	expect(t, err, nil)
+	expect(t, tags, []string{"a"})
+}
+
+func TestLayeredSourceUnset(t *testing.T) {
+	base := NewMapInputSource("base.yaml", map[interface{}]interface{}{
+		"name":    "base",
+		"retries": 3,
+	})
+	override := NewMapInputSource("override.yaml", map[interface{}]interface{}{
+		"name": nil,
+	})
+	src := NewLayeredSource(base, override)
+
+	// null in a higher layer unsets the value of the lower layers
+	name, err := src.String("name")
+	expect(t, err, nil)
+	expect(t, name, "")
+
+	retries, err := src.Int("retries")
+	expect(t, err, nil)
+	expect(t, retries, 3)
+}
+
+func TestLayeredSourceTypeError(t *testing.T) {
+	src := NewLayeredSource(
+		NewMapInputSource("a", map[interface{}]interface{}{"count": 1}),
+		NewMapInputSource("b", map[interface{}]interface{}{"count": "one"}),
+	)
+
+	_, err := src.Int("count")
+	refute(t, nil, err)
+}
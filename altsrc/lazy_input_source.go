@@ -0,0 +1,126 @@
+package altsrc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// lazySource implements InputSourceContext on top of a source that is only
+// loaded when one of its values is first looked up.
+type lazySource struct {
+	path   string
+	loader func(string) (InputSourceContext, error)
+
+	once sync.Once
+	src  InputSourceContext
+	err  error
+}
+
+// NewLazyFileSource creates an InputSourceContext that calls loader with
+// path, e.g. NewYamlSourceFromFile, when a value is first looked up rather
+// than when it is created, so that a large file is not read and parsed when
+// no value is needed from it. The loaded source is kept for later lookups,
+// and an error of loader is returned by every lookup.
+func NewLazyFileSource(path string, loader func(string) (InputSourceContext, error)) InputSourceContext {
+	return &lazySource{path: path, loader: loader}
+}
+
+func (ls *lazySource) load() (InputSourceContext, error) {
+	ls.once.Do(func() {
+		ls.src, ls.err = ls.loader(ls.path)
+	})
+	return ls.src, ls.err
+}
+
+// Source returns the path of the file, without loading it
+func (ls *lazySource) Source() string {
+	return ls.path
+}
+
+// isSet reports whether the loaded source has a value for name. A source
+// that failed to load reports every name as set, so that layered lookups
+// return its error.
+func (ls *lazySource) isSet(name string) bool {
+	src, err := ls.load()
+	if err != nil {
+		return true
+	}
+	if kc, ok := src.(keyChecker); ok {
+		return kc.isSet(name)
+	}
+	return true
+}
+
+// Int returns an int from the loaded source
+func (ls *lazySource) Int(name string) (int, error) {
+	src, err := ls.load()
+	if err != nil {
+		return 0, err
+	}
+	return src.Int(name)
+}
+
+// Duration returns a duration from the loaded source
+func (ls *lazySource) Duration(name string) (time.Duration, error) {
+	src, err := ls.load()
+	if err != nil {
+		return 0, err
+	}
+	return src.Duration(name)
+}
+
+// Float64 returns a float64 from the loaded source
+func (ls *lazySource) Float64(name string) (float64, error) {
+	src, err := ls.load()
+	if err != nil {
+		return 0, err
+	}
+	return src.Float64(name)
+}
+
+// String returns a string from the loaded source
+func (ls *lazySource) String(name string) (string, error) {
+	src, err := ls.load()
+	if err != nil {
+		return "", err
+	}
+	return src.String(name)
+}
+
+// StringSlice returns a []string from the loaded source
+func (ls *lazySource) StringSlice(name string) ([]string, error) {
+	src, err := ls.load()
+	if err != nil {
+		return nil, err
+	}
+	return src.StringSlice(name)
+}
+
+// IntSlice returns an []int from the loaded source
+func (ls *lazySource) IntSlice(name string) ([]int, error) {
+	src, err := ls.load()
+	if err != nil {
+		return nil, err
+	}
+	return src.IntSlice(name)
+}
+
+// Generic returns a cli.Generic from the loaded source
+func (ls *lazySource) Generic(name string) (cli.Generic, error) {
+	src, err := ls.load()
+	if err != nil {
+		return nil, err
+	}
+	return src.Generic(name)
+}
+
+// Bool returns a bool from the loaded source
+func (ls *lazySource) Bool(name string) (bool, error) {
+	src, err := ls.load()
+	if err != nil {
+		return false, err
+	}
+	return src.Bool(name)
+}
@@ -0,0 +1,46 @@
+package altsrc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLazyFileSource(t *testing.T) {
+	calls := 0
+	src := NewLazyFileSource("config.yaml", func(path string) (InputSourceContext, error) {
+		calls++
+		return NewMapInputSource(path, map[interface{}]interface{}{
+			"name":    "app",
+			"retries": 3,
+		}), nil
+	})
+
+	expect(t, src.Source(), "config.yaml")
+	expect(t, calls, 0)
+
+	name, err := src.String("name")
+	expect(t, err, nil)
+	expect(t, name, "app")
+	expect(t, calls, 1)
+
+	retries, err := src.Int("retries")
+	expect(t, err, nil)
+	expect(t, retries, 3)
+	expect(t, calls, 1)
+}
+
+func TestLazyFileSourceError(t *testing.T) {
+	src := NewLazyFileSource("config.yaml", func(path string) (InputSourceContext, error) {
+		return nil, errors.New("bad yaml")
+	})
+
+	_, err := src.String("name")
+	expect(t, err, errors.New("bad yaml"))
+	_, err = src.Bool("verbose")
+	expect(t, err, errors.New("bad yaml"))
+
+	// the error is returned through a layered source as well
+	layered := NewLayeredSource(NewMapInputSource("base.yaml", map[interface{}]interface{}{"name": "base"}), src)
+	_, err = layered.String("name")
+	expect(t, err, errors.New("bad yaml"))
+}
@@ -2,7 +2,10 @@ package altsrc
 
 import (
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,6 +17,10 @@ import (
 type MapInputSource struct {
 	file     string
 	valueMap map[interface{}]interface{}
+
+	// timestampLayout is the layout timestamp strings are parsed with,
+	// time.RFC3339 if empty
+	timestampLayout string
 }
 
 // NewMapInputSource creates a new MapInputSource for implementing custom input sources.
@@ -23,26 +30,123 @@ func NewMapInputSource(file string, valueMap map[interface{}]interface{}) *MapIn
 
 // nestedVal checks if the name has '.' delimiters.
 // If so, it tries to traverse the tree by the '.' delimited sections to find
-// a nested value for the key.
+// a nested value for the key. Keys inherited through YAML merge keys are
+// found as well, see mapVal.
 func nestedVal(name string, tree map[interface{}]interface{}) (interface{}, bool) {
-	if sections := strings.Split(name, "."); len(sections) > 1 {
-		node := tree
-		for _, section := range sections[:len(sections)-1] {
-			child, ok := node[section]
-			if !ok {
-				return nil, false
+	sections := strings.Split(name, ".")
+	node := tree
+	for _, section := range sections[:len(sections)-1] {
+		child, ok := mapVal(node, section)
+		if !ok {
+			return nil, false
+		}
+		ctype, ok := child.(map[interface{}]interface{})
+		if !ok {
+			return nil, false
+		}
+		node = ctype
+	}
+	return mapVal(node, sections[len(sections)-1])
+}
+
+// mapVal returns the value of key in node. A key node does not have is
+// looked up in the maps merged into node with the YAML merge key "<<", which
+// holds a map or a list of maps, the first map in the list that has the key
+// winning, as decoders that do not resolve merge keys leave them in the map.
+func mapVal(node map[interface{}]interface{}, key string) (interface{}, bool) {
+	if val, ok := node[key]; ok {
+		return val, true
+	}
+
+	var merged []interface{}
+	switch m := node["<<"].(type) {
+	case map[interface{}]interface{}:
+		merged = []interface{}{m}
+	case []interface{}:
+		merged = m
+	}
+	for _, m := range merged {
+		if mmap, ok := m.(map[interface{}]interface{}); ok {
+			if val, ok := mapVal(mmap, key); ok {
+				return val, true
 			}
-			ctype, ok := child.(map[interface{}]interface{})
-			if !ok {
-				return nil, false
+		}
+	}
+	return nil, false
+}
+
+// isSet reports whether the map has a value for name, either directly or
+// nested under '.' delimited sections
+func (fsm *MapInputSource) isSet(name string) bool {
+	if _, exists := fsm.valueMap[name]; exists {
+		return true
+	}
+	_, exists := nestedVal(name, fsm.valueMap)
+	return exists
+}
+
+// IsUnset reports whether name is explicitly unset in the map, i.e. present
+// with a nil value such as null in YAML or JSON, as opposed to absent. The
+// getters return the zero value of their type and no error for such a name,
+// and a layered source takes it from the map rather than from the sources
+// with lower precedence, so a null value unsets the value of those sources.
+func (fsm *MapInputSource) IsUnset(name string) bool {
+	if value, exists := fsm.valueMap[name]; exists {
+		return value == nil
+	}
+	value, exists := nestedVal(name, fsm.valueMap)
+	return exists && value == nil
+}
+
+// UnknownKeys returns the '.' delimited keys of the map that are not among
+// known, sorted, e.g. to fail on misspelled keys in a config file given the
+// names of the flags. Nested maps are walked unless their own key is known,
+// and keys merged in with the YAML merge key count as keys of the map they
+// are merged into.
+func (fsm *MapInputSource) UnknownKeys(known []string) []string {
+	knownKeys := make(map[string]bool, len(known))
+	for _, name := range known {
+		knownKeys[name] = true
+	}
+
+	unknownKeys := map[string]bool{}
+	collectUnknownKeys("", fsm.valueMap, knownKeys, unknownKeys)
+
+	var unknown []string
+	for key := range unknownKeys {
+		unknown = append(unknown, key)
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+func collectUnknownKeys(prefix string, node map[interface{}]interface{}, known, unknown map[string]bool) {
+	for k, v := range node {
+		key := fmt.Sprintf("%v", k)
+		if key == "<<" {
+			switch m := v.(type) {
+			case map[interface{}]interface{}:
+				collectUnknownKeys(prefix, m, known, unknown)
+			case []interface{}:
+				for _, item := range m {
+					if mmap, ok := item.(map[interface{}]interface{}); ok {
+						collectUnknownKeys(prefix, mmap, known, unknown)
+					}
+				}
 			}
-			node = ctype
+			continue
+		}
+
+		key = prefix + key
+		if known[key] {
+			continue
 		}
-		if val, ok := node[sections[len(sections)-1]]; ok {
-			return val, true
+		if child, ok := v.(map[interface{}]interface{}); ok && len(child) > 0 {
+			collectUnknownKeys(key+".", child, known, unknown)
+			continue
 		}
+		unknown[key] = true
 	}
-	return nil, false
 }
 
 // Source returns the path of the source file
@@ -54,19 +158,77 @@ func (fsm *MapInputSource) Source() string {
 func (fsm *MapInputSource) Int(name string) (int, error) {
 	otherGenericValue, exists := fsm.valueMap[name]
 	if exists {
-		otherValue, isType := otherGenericValue.(int)
-		if !isType {
-			return 0, incorrectTypeForFlagError(name, "int", otherGenericValue)
+		if otherGenericValue == nil {
+			return 0, nil
 		}
-		return otherValue, nil
+		return castInt(name, otherGenericValue)
 	}
 	nestedGenericValue, exists := nestedVal(name, fsm.valueMap)
 	if exists {
-		otherValue, isType := nestedGenericValue.(int)
-		if !isType {
-			return 0, incorrectTypeForFlagError(name, "int", nestedGenericValue)
+		if nestedGenericValue == nil {
+			return 0, nil
 		}
-		return otherValue, nil
+		return castInt(name, nestedGenericValue)
+	}
+
+	return 0, nil
+}
+
+// Int64 returns an int64 from the map if it exists otherwise returns 0
+func (fsm *MapInputSource) Int64(name string) (int64, error) {
+	otherGenericValue, exists := fsm.valueMap[name]
+	if exists {
+		if otherGenericValue == nil {
+			return 0, nil
+		}
+		return castInt64(name, otherGenericValue)
+	}
+	nestedGenericValue, exists := nestedVal(name, fsm.valueMap)
+	if exists {
+		if nestedGenericValue == nil {
+			return 0, nil
+		}
+		return castInt64(name, nestedGenericValue)
+	}
+
+	return 0, nil
+}
+
+// Uint returns an uint from the map if it exists otherwise returns 0
+func (fsm *MapInputSource) Uint(name string) (uint, error) {
+	otherGenericValue, exists := fsm.valueMap[name]
+	if exists {
+		if otherGenericValue == nil {
+			return 0, nil
+		}
+		return castUint(name, otherGenericValue)
+	}
+	nestedGenericValue, exists := nestedVal(name, fsm.valueMap)
+	if exists {
+		if nestedGenericValue == nil {
+			return 0, nil
+		}
+		return castUint(name, nestedGenericValue)
+	}
+
+	return 0, nil
+}
+
+// Uint64 returns an uint64 from the map if it exists otherwise returns 0
+func (fsm *MapInputSource) Uint64(name string) (uint64, error) {
+	otherGenericValue, exists := fsm.valueMap[name]
+	if exists {
+		if otherGenericValue == nil {
+			return 0, nil
+		}
+		return castUint64(name, otherGenericValue)
+	}
+	nestedGenericValue, exists := nestedVal(name, fsm.valueMap)
+	if exists {
+		if nestedGenericValue == nil {
+			return 0, nil
+		}
+		return castUint64(name, nestedGenericValue)
 	}
 
 	return 0, nil
@@ -76,24 +238,149 @@ func (fsm *MapInputSource) Int(name string) (int, error) {
 func (fsm *MapInputSource) Duration(name string) (time.Duration, error) {
 	otherGenericValue, exists := fsm.valueMap[name]
 	if exists {
+		if otherGenericValue == nil {
+			return 0, nil
+		}
 		return castDuration(name, otherGenericValue)
 	}
 	nestedGenericValue, exists := nestedVal(name, fsm.valueMap)
 	if exists {
+		if nestedGenericValue == nil {
+			return 0, nil
+		}
 		return castDuration(name, nestedGenericValue)
 	}
 
 	return 0, nil
 }
 
+// castDuration converts value to a time.Duration. Besides durations it
+// accepts strings parsed by time.ParseDuration, e.g. "30s", and numbers of
+// seconds, e.g. 30 or 1.5.
 func castDuration(name string, value interface{}) (time.Duration, error) {
-	if otherValue, isType := value.(time.Duration); isType {
+	switch v := value.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		if parsedValue, err := time.ParseDuration(v); err == nil {
+			return parsedValue, nil
+		}
+	case float32:
+		return time.Duration(float64(v) * float64(time.Second)), nil
+	case float64:
+		return time.Duration(v * float64(time.Second)), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		if seconds, err := castInt64(name, v); err == nil {
+			return time.Duration(seconds) * time.Second, nil
+		}
+	}
+	return 0, incorrectTypeForFlagError(name, "duration", value)
+}
+
+// castInt64 converts value to an int64. Besides integers it accepts
+// integral floats, as decoded from JSON, and numeric strings.
+func castInt64(name string, value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint, uint8, uint16, uint32, uint64:
+		if u, err := castUint64(name, v); err == nil && u <= math.MaxInt64 {
+			return int64(u), nil
+		}
+	case float32:
+		return castInt64(name, float64(v))
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+			return int64(v), nil
+		}
+	case string:
+		if parsedValue, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+			return parsedValue, nil
+		}
+		if parsedValue, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return castInt64(name, parsedValue)
+		}
+	}
+	return 0, incorrectTypeForFlagError(name, "int64", value)
+}
+
+// castUint64 converts value to an uint64, accepting the same values as
+// castInt64 as long as they are not negative.
+func castUint64(name string, value interface{}) (uint64, error) {
+	switch v := value.(type) {
+	case uint:
+		return uint64(v), nil
+	case uint8:
+		return uint64(v), nil
+	case uint16:
+		return uint64(v), nil
+	case uint32:
+		return uint64(v), nil
+	case uint64:
+		return v, nil
+	case float64:
+		if v == math.Trunc(v) && v >= 0 && v < math.MaxUint64 {
+			return uint64(v), nil
+		}
+	case string:
+		if parsedValue, err := strconv.ParseUint(strings.TrimSpace(v), 10, 64); err == nil {
+			return parsedValue, nil
+		}
+		if parsedValue, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return castUint64(name, parsedValue)
+		}
+	default:
+		if i, err := castInt64(name, v); err == nil && i >= 0 {
+			return uint64(i), nil
+		}
+	}
+	return 0, incorrectTypeForFlagError(name, "uint64", value)
+}
+
+func castInt(name string, value interface{}) (int, error) {
+	i, err := castInt64(name, value)
+	if err != nil || int64(int(i)) != i {
+		return 0, incorrectTypeForFlagError(name, "int", value)
+	}
+	return int(i), nil
+}
+
+func castUint(name string, value interface{}) (uint, error) {
+	u, err := castUint64(name, value)
+	if err != nil || uint64(uint(u)) != u {
+		return 0, incorrectTypeForFlagError(name, "uint", value)
+	}
+	return uint(u), nil
+}
+
+func castFloat64(name string, value interface{}) (float64, error) {
+	if otherValue, isType := value.(float64); isType {
 		return otherValue, nil
 	}
 	otherStringValue, isType := value.(string)
-	parsedValue, err := time.ParseDuration(otherStringValue)
+	parsedValue, err := strconv.ParseFloat(strings.TrimSpace(otherStringValue), 64)
 	if !isType || err != nil {
-		return 0, incorrectTypeForFlagError(name, "duration", value)
+		return 0, incorrectTypeForFlagError(name, "float64", value)
+	}
+	return parsedValue, nil
+}
+
+func castBool(name string, value interface{}) (bool, error) {
+	if otherValue, isType := value.(bool); isType {
+		return otherValue, nil
+	}
+	otherStringValue, isType := value.(string)
+	parsedValue, err := strconv.ParseBool(strings.TrimSpace(otherStringValue))
+	if !isType || err != nil {
+		return false, incorrectTypeForFlagError(name, "bool", value)
 	}
 	return parsedValue, nil
 }
@@ -102,6 +389,9 @@ func castDuration(name string, value interface{}) (time.Duration, error) {
 func (fsm *MapInputSource) Float64(name string) (float64, error) {
 	otherGenericValue, exists := fsm.valueMap[name]
 	if exists {
+		if otherGenericValue == nil {
+			return 0, nil
+		}
 		otherValue, isType := otherGenericValue.(float64)
 		if !isType {
 			return 0, incorrectTypeForFlagError(name, "float64", otherGenericValue)
@@ -110,6 +400,9 @@ func (fsm *MapInputSource) Float64(name string) (float64, error) {
 	}
 	nestedGenericValue, exists := nestedVal(name, fsm.valueMap)
 	if exists {
+		if nestedGenericValue == nil {
+			return 0, nil
+		}
 		otherValue, isType := nestedGenericValue.(float64)
 		if !isType {
 			return 0, incorrectTypeForFlagError(name, "float64", nestedGenericValue)
@@ -124,6 +417,9 @@ func (fsm *MapInputSource) Float64(name string) (float64, error) {
 func (fsm *MapInputSource) String(name string) (string, error) {
 	otherGenericValue, exists := fsm.valueMap[name]
 	if exists {
+		if otherGenericValue == nil {
+			return "", nil
+		}
 		otherValue, isType := otherGenericValue.(string)
 		if !isType {
 			return "", incorrectTypeForFlagError(name, "string", otherGenericValue)
@@ -132,6 +428,9 @@ func (fsm *MapInputSource) String(name string) (string, error) {
 	}
 	nestedGenericValue, exists := nestedVal(name, fsm.valueMap)
 	if exists {
+		if nestedGenericValue == nil {
+			return "", nil
+		}
 		otherValue, isType := nestedGenericValue.(string)
 		if !isType {
 			return "", incorrectTypeForFlagError(name, "string", nestedGenericValue)
@@ -142,7 +441,8 @@ func (fsm *MapInputSource) String(name string) (string, error) {
 	return "", nil
 }
 
-// StringSlice returns an []string from the map if it exists otherwise returns nil
+// StringSlice returns an []string from the map if it exists otherwise returns nil.
+// A single string is returned as a slice of one, e.g. for "tags: foo" in YAML
 func (fsm *MapInputSource) StringSlice(name string) ([]string, error) {
 	otherGenericValue, exists := fsm.valueMap[name]
 	if !exists {
@@ -151,6 +451,14 @@ func (fsm *MapInputSource) StringSlice(name string) ([]string, error) {
 			return nil, nil
 		}
 	}
+	if otherGenericValue == nil {
+		return nil, nil
+	}
+
+	// a single value may be given without the list syntax
+	if stringValue, isType := otherGenericValue.(string); isType {
+		return []string{stringValue}, nil
+	}
 
 	otherValue, isType := otherGenericValue.([]interface{})
 	if !isType {
@@ -180,6 +488,9 @@ func (fsm *MapInputSource) IntSlice(name string) ([]int, error) {
 			return nil, nil
 		}
 	}
+	if otherGenericValue == nil {
+		return nil, nil
+	}
 
 	otherValue, isType := otherGenericValue.([]interface{})
 	if !isType {
@@ -200,10 +511,183 @@ func (fsm *MapInputSource) IntSlice(name string) ([]int, error) {
 	return intSlice, nil
 }
 
+// StringMap returns an map[string]string from the map if it exists otherwise returns nil
+func (fsm *MapInputSource) StringMap(name string) (map[string]string, error) {
+	otherGenericValue, exists := fsm.valueMap[name]
+	if !exists {
+		otherGenericValue, exists = nestedVal(name, fsm.valueMap)
+		if !exists {
+			return nil, nil
+		}
+	}
+	if otherGenericValue == nil {
+		return nil, nil
+	}
+
+	otherValue, isType := otherGenericValue.(map[interface{}]interface{})
+	if !isType {
+		return nil, incorrectTypeForFlagError(name, "map[interface{}]interface{}", otherGenericValue)
+	}
+
+	var stringMap = make(map[string]string, len(otherValue))
+	for k, v := range otherValue {
+		stringValue, isType := v.(string)
+
+		if !isType {
+			return nil, incorrectTypeForFlagError(fmt.Sprintf("%s.%v", name, k), "string", v)
+		}
+
+		stringMap[fmt.Sprintf("%v", k)] = stringValue
+	}
+
+	return stringMap, nil
+}
+
+// Size returns a number of bytes from the map if it exists otherwise
+// returns 0. Strings are parsed with cli.ParseSize, e.g. "512MB", and
+// numbers are taken as bytes.
+func (fsm *MapInputSource) Size(name string) (int64, error) {
+	otherGenericValue, exists := fsm.valueMap[name]
+	if !exists {
+		otherGenericValue, exists = nestedVal(name, fsm.valueMap)
+		if !exists {
+			return 0, nil
+		}
+	}
+	if otherGenericValue == nil {
+		return 0, nil
+	}
+
+	if otherStringValue, isType := otherGenericValue.(string); isType {
+		parsedValue, err := cli.ParseSize(otherStringValue)
+		if err != nil {
+			return 0, fmt.Errorf("could not parse %q as size for flag '%s': %s", otherStringValue, name, err)
+		}
+		return parsedValue, nil
+	}
+
+	parsedValue, err := castInt64(name, otherGenericValue)
+	if err != nil {
+		return 0, incorrectTypeForFlagError(name, "size", otherGenericValue)
+	}
+	return parsedValue, nil
+}
+
+// SubSources returns an InputSourceContext for each of the maps in the list
+// held by name, e.g. one per job in a list of jobs, otherwise returns nil.
+// The sources share the file and timestamp layout of this source.
+func (fsm *MapInputSource) SubSources(name string) ([]InputSourceContext, error) {
+	otherGenericValue, exists := fsm.valueMap[name]
+	if !exists {
+		otherGenericValue, exists = nestedVal(name, fsm.valueMap)
+		if !exists {
+			return nil, nil
+		}
+	}
+	if otherGenericValue == nil {
+		return nil, nil
+	}
+
+	otherValue, isType := otherGenericValue.([]interface{})
+	if !isType {
+		return nil, incorrectTypeForFlagError(name, "[]interface{}", otherGenericValue)
+	}
+
+	var sources = make([]InputSourceContext, 0, len(otherValue))
+	for i, v := range otherValue {
+		valueMap, isType := v.(map[interface{}]interface{})
+		if !isType {
+			return nil, incorrectTypeForFlagError(fmt.Sprintf("%s[%d]", name, i), "map[interface{}]interface{}", v)
+		}
+
+		sources = append(sources, &MapInputSource{file: fsm.file, valueMap: valueMap, timestampLayout: fsm.timestampLayout})
+	}
+
+	return sources, nil
+}
+
+// SetTimestampLayout sets the layout used by Timestamp and TimestampSlice to
+// parse strings, which defaults to time.RFC3339
+func (fsm *MapInputSource) SetTimestampLayout(layout string) {
+	fsm.timestampLayout = layout
+}
+
+// Timestamp returns a time.Time from the map if it exists otherwise returns
+// the zero time. Strings are parsed with the layout of the source.
+func (fsm *MapInputSource) Timestamp(name string) (time.Time, error) {
+	otherGenericValue, exists := fsm.valueMap[name]
+	if !exists {
+		otherGenericValue, exists = nestedVal(name, fsm.valueMap)
+		if !exists {
+			return time.Time{}, nil
+		}
+	}
+	if otherGenericValue == nil {
+		return time.Time{}, nil
+	}
+
+	return fsm.castTimestamp(name, otherGenericValue)
+}
+
+// TimestampSlice returns a []time.Time from the map if it exists otherwise
+// returns nil. Strings are parsed with the layout of the source.
+func (fsm *MapInputSource) TimestampSlice(name string) ([]time.Time, error) {
+	otherGenericValue, exists := fsm.valueMap[name]
+	if !exists {
+		otherGenericValue, exists = nestedVal(name, fsm.valueMap)
+		if !exists {
+			return nil, nil
+		}
+	}
+	if otherGenericValue == nil {
+		return nil, nil
+	}
+
+	otherValue, isType := otherGenericValue.([]interface{})
+	if !isType {
+		return nil, incorrectTypeForFlagError(name, "[]interface{}", otherGenericValue)
+	}
+
+	var timestampSlice = make([]time.Time, 0, len(otherValue))
+	for i, v := range otherValue {
+		timestamp, err := fsm.castTimestamp(fmt.Sprintf("%s[%d]", name, i), v)
+		if err != nil {
+			return nil, err
+		}
+
+		timestampSlice = append(timestampSlice, timestamp)
+	}
+
+	return timestampSlice, nil
+}
+
+// castTimestamp converts value, a time.Time or a string in the layout of
+// the source, to a time.Time
+func (fsm *MapInputSource) castTimestamp(name string, value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		layout := fsm.timestampLayout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		parsedValue, err := time.Parse(layout, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("could not parse %q as timestamp for flag '%s': %s", v, name, err)
+		}
+		return parsedValue, nil
+	}
+	return time.Time{}, incorrectTypeForFlagError(name, "timestamp", value)
+}
+
 // Generic returns an cli.Generic from the map if it exists otherwise returns nil
 func (fsm *MapInputSource) Generic(name string) (cli.Generic, error) {
 	otherGenericValue, exists := fsm.valueMap[name]
 	if exists {
+		if otherGenericValue == nil {
+			return nil, nil
+		}
 		otherValue, isType := otherGenericValue.(cli.Generic)
 		if !isType {
 			return nil, incorrectTypeForFlagError(name, "cli.Generic", otherGenericValue)
@@ -212,6 +696,9 @@ func (fsm *MapInputSource) Generic(name string) (cli.Generic, error) {
 	}
 	nestedGenericValue, exists := nestedVal(name, fsm.valueMap)
 	if exists {
+		if nestedGenericValue == nil {
+			return nil, nil
+		}
 		otherValue, isType := nestedGenericValue.(cli.Generic)
 		if !isType {
 			return nil, incorrectTypeForFlagError(name, "cli.Generic", nestedGenericValue)
@@ -226,6 +713,9 @@ func (fsm *MapInputSource) Generic(name string) (cli.Generic, error) {
 func (fsm *MapInputSource) Bool(name string) (bool, error) {
 	otherGenericValue, exists := fsm.valueMap[name]
 	if exists {
+		if otherGenericValue == nil {
+			return false, nil
+		}
 		otherValue, isType := otherGenericValue.(bool)
 		if !isType {
 			return false, incorrectTypeForFlagError(name, "bool", otherGenericValue)
@@ -234,6 +724,9 @@ func (fsm *MapInputSource) Bool(name string) (bool, error) {
 	}
 	nestedGenericValue, exists := nestedVal(name, fsm.valueMap)
 	if exists {
+		if nestedGenericValue == nil {
+			return false, nil
+		}
 		otherValue, isType := nestedGenericValue.(bool)
 		if !isType {
 			return false, incorrectTypeForFlagError(name, "bool", nestedGenericValue)
@@ -244,6 +737,19 @@ func (fsm *MapInputSource) Bool(name string) (bool, error) {
 	return false, nil
 }
 
+// TypeMismatchError is returned by the getters of a MapInputSource when the
+// value of a flag in the map does not have the type of the flag. Flag is the
+// name of the flag, Expected the expected type and Actual the type found.
+type TypeMismatchError struct {
+	Flag     string
+	Expected string
+	Actual   string
+}
+
+func (e *TypeMismatchError) Error() string {
+	return fmt.Sprintf("Mismatched type for flag '%s'. Expected '%s' but actual is '%s'", e.Flag, e.Expected, e.Actual)
+}
+
 func incorrectTypeForFlagError(name, expectedTypeName string, value interface{}) error {
 	valueType := reflect.TypeOf(value)
 	valueTypeName := ""
@@ -251,5 +757,5 @@ func incorrectTypeForFlagError(name, expectedTypeName string, value interface{})
 		valueTypeName = valueType.Name()
 	}
 
-	return fmt.Errorf("Mismatched type for flag '%s'. Expected '%s' but actual is '%s'", name, expectedTypeName, valueTypeName)
+	return &TypeMismatchError{Flag: name, Expected: expectedTypeName, Actual: valueTypeName}
 }
@@ -1,6 +1,7 @@
 package altsrc
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -11,7 +12,11 @@ func TestMapDuration(t *testing.T) {
 		map[interface{}]interface{}{
 			"duration_of_duration_type": time.Minute,
 			"duration_of_string_type":   "1m",
-			"duration_of_int_type":      1000,
+			"duration_of_int_type":      30,
+			"duration_of_int64_type":    int64(2),
+			"duration_of_float_type":    1.5,
+			"duration_of_bad_string":    "30",
+			"duration_of_bool_type":     true,
 		})
 	d, err := inputSource.Duration("duration_of_duration_type")
 	expect(t, time.Minute, d)
@@ -19,6 +24,368 @@ func TestMapDuration(t *testing.T) {
 	d, err = inputSource.Duration("duration_of_string_type")
 	expect(t, time.Minute, d)
 	expect(t, nil, err)
-	_, err = inputSource.Duration("duration_of_int_type")
+	d, err = inputSource.Duration("duration_of_int_type")
+	expect(t, 30*time.Second, d)
+	expect(t, nil, err)
+	d, err = inputSource.Duration("duration_of_int64_type")
+	expect(t, 2*time.Second, d)
+	expect(t, nil, err)
+	d, err = inputSource.Duration("duration_of_float_type")
+	expect(t, 1500*time.Millisecond, d)
+	expect(t, nil, err)
+	_, err = inputSource.Duration("duration_of_bad_string")
+	refute(t, nil, err)
+	_, err = inputSource.Duration("duration_of_bool_type")
+	refute(t, nil, err)
+}
+
+func TestMapStringMap(t *testing.T) {
+	inputSource := NewMapInputSource(
+		"test",
+		map[interface{}]interface{}{
+			"labels": map[interface{}]interface{}{
+				"env":  "prod",
+				"team": "core",
+			},
+			"nested": map[interface{}]interface{}{
+				"labels": map[interface{}]interface{}{
+					"env": "dev",
+				},
+			},
+			"not_a_map":    "env=prod",
+			"not_a_string": map[interface{}]interface{}{"replicas": 3},
+		})
+	m, err := inputSource.StringMap("labels")
+	expect(t, map[string]string{"env": "prod", "team": "core"}, m)
+	expect(t, nil, err)
+	m, err = inputSource.StringMap("nested.labels")
+	expect(t, map[string]string{"env": "dev"}, m)
+	expect(t, nil, err)
+	_, err = inputSource.StringMap("not_a_map")
+	refute(t, nil, err)
+	_, err = inputSource.StringMap("not_a_string")
+	refute(t, nil, err)
+}
+
+func TestMapIntCoercion(t *testing.T) {
+	inputSource := NewMapInputSource(
+		"test",
+		map[interface{}]interface{}{
+			"int":             8080,
+			"int64":           int64(8080),
+			"uint64":          uint64(8080),
+			"float":           float64(8080),
+			"string":          "8080",
+			"float_string":    " 8080.0 ",
+			"negative":        -1,
+			"negative_string": "-1",
+			"fraction":        8080.5,
+			"word":            "eighty",
+			"bool":            true,
+			"nested": map[interface{}]interface{}{
+				"port": float64(8080),
+			},
+		})
+
+	for _, name := range []string{"int", "int64", "uint64", "float", "string", "float_string", "nested.port"} {
+		i, err := inputSource.Int(name)
+		expect(t, err, nil)
+		expect(t, i, 8080)
+
+		i64, err := inputSource.Int64(name)
+		expect(t, err, nil)
+		expect(t, i64, int64(8080))
+
+		u, err := inputSource.Uint(name)
+		expect(t, err, nil)
+		expect(t, u, uint(8080))
+
+		u64, err := inputSource.Uint64(name)
+		expect(t, err, nil)
+		expect(t, u64, uint64(8080))
+	}
+
+	for _, name := range []string{"negative", "negative_string"} {
+		i, err := inputSource.Int(name)
+		expect(t, err, nil)
+		expect(t, i, -1)
+
+		_, err = inputSource.Uint(name)
+		refute(t, nil, err)
+		_, err = inputSource.Uint64(name)
+		refute(t, nil, err)
+	}
+
+	for _, name := range []string{"fraction", "word", "bool"} {
+		_, err := inputSource.Int(name)
+		refute(t, nil, err)
+		_, err = inputSource.Int64(name)
+		refute(t, nil, err)
+		_, err = inputSource.Uint(name)
+		refute(t, nil, err)
+		_, err = inputSource.Uint64(name)
+		refute(t, nil, err)
+	}
+
+	i64, err := inputSource.Int64("missing")
+	expect(t, err, nil)
+	expect(t, i64, int64(0))
+}
+
+func TestMapTimestamp(t *testing.T) {
+	inputSource := NewMapInputSource(
+		"test",
+		map[interface{}]interface{}{
+			"since":     "2006-01-02T15:04:05Z",
+			"malformed": "2006-01-02",
+			"number":    20060102,
+			"range": map[interface{}]interface{}{
+				"dates": []interface{}{"2006-01-02T15:04:05Z", time.Date(2007, 1, 2, 0, 0, 0, 0, time.UTC)},
+				"bad":   []interface{}{"2006-01-02T15:04:05Z", 1},
+			},
+		})
+	expected := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	ts, err := inputSource.Timestamp("since")
+	expect(t, nil, err)
+	expect(t, expected, ts)
+
+	_, err = inputSource.Timestamp("malformed")
+	refute(t, nil, err)
+
+	_, err = inputSource.Timestamp("number")
+	expect(t, "Mismatched type for flag 'number'. Expected 'timestamp' but actual is 'int'", err.Error())
+
+	ts, err = inputSource.Timestamp("missing")
+	expect(t, nil, err)
+	expect(t, time.Time{}, ts)
+
+	slice, err := inputSource.TimestampSlice("range.dates")
+	expect(t, nil, err)
+	expect(t, []time.Time{expected, time.Date(2007, 1, 2, 0, 0, 0, 0, time.UTC)}, slice)
+
+	_, err = inputSource.TimestampSlice("range.bad")
+	expect(t, "Mismatched type for flag 'range.bad[1]'. Expected 'timestamp' but actual is 'int'", err.Error())
+
+	inputSource.SetTimestampLayout("2006-01-02")
+	ts, err = inputSource.Timestamp("malformed")
+	expect(t, nil, err)
+	expect(t, time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC), ts)
+}
+
+func TestMapSubSources(t *testing.T) {
+	inputSource := NewMapInputSource(
+		"test",
+		map[interface{}]interface{}{
+			"jobs": []interface{}{
+				map[interface{}]interface{}{"name": "build", "retries": 2},
+				map[interface{}]interface{}{"name": "deploy", "target": map[interface{}]interface{}{"env": "prod"}},
+			},
+			"not_a_list": map[interface{}]interface{}{"name": "build"},
+			"not_maps":   []interface{}{map[interface{}]interface{}{"name": "build"}, "deploy"},
+		})
+
+	jobs, err := inputSource.SubSources("jobs")
+	expect(t, nil, err)
+	expect(t, 2, len(jobs))
+
+	name, err := jobs[0].String("name")
+	expect(t, nil, err)
+	expect(t, "build", name)
+	retries, err := jobs[0].Int("retries")
+	expect(t, nil, err)
+	expect(t, 2, retries)
+
+	name, err = jobs[1].String("name")
+	expect(t, nil, err)
+	expect(t, "deploy", name)
+	env, err := jobs[1].String("target.env")
+	expect(t, nil, err)
+	expect(t, "prod", env)
+	expect(t, "test", jobs[1].Source())
+
+	_, err = inputSource.SubSources("not_a_list")
+	expect(t, "Mismatched type for flag 'not_a_list'. Expected '[]interface{}' but actual is ''", err.Error())
+
+	_, err = inputSource.SubSources("not_maps")
+	expect(t, "Mismatched type for flag 'not_maps[1]'. Expected 'map[interface{}]interface{}' but actual is 'string'", err.Error())
+
+	jobs, err = inputSource.SubSources("missing")
+	expect(t, nil, err)
+	expect(t, 0, len(jobs))
+}
+
+func TestMapSize(t *testing.T) {
+	inputSource := NewMapInputSource(
+		"test",
+		map[interface{}]interface{}{
+			"max_size": "512MB",
+			"limits": map[interface{}]interface{}{
+				"cache": "1KiB",
+			},
+			"bytes":      2048,
+			"bad_suffix": "10XB",
+			"bool":       true,
+		})
+
+	size, err := inputSource.Size("max_size")
+	expect(t, nil, err)
+	expect(t, int64(512000000), size)
+
+	size, err = inputSource.Size("limits.cache")
+	expect(t, nil, err)
+	expect(t, int64(1024), size)
+
+	size, err = inputSource.Size("bytes")
+	expect(t, nil, err)
+	expect(t, int64(2048), size)
+
+	_, err = inputSource.Size("bad_suffix")
+	refute(t, nil, err)
+	_, err = inputSource.Size("bool")
+	refute(t, nil, err)
+
+	size, err = inputSource.Size("missing")
+	expect(t, nil, err)
+	expect(t, int64(0), size)
+}
+
+func TestMapUnset(t *testing.T) {
+	inputSource := NewMapInputSource(
+		"test",
+		map[interface{}]interface{}{
+			"name":    nil,
+			"timeout": nil,
+			"set":     "value",
+			"db": map[interface{}]interface{}{
+				"port": nil,
+			},
+		})
+
+	expect(t, inputSource.IsUnset("name"), true)
+	expect(t, inputSource.IsUnset("db.port"), true)
+	expect(t, inputSource.IsUnset("set"), false)
+	expect(t, inputSource.IsUnset("missing"), false)
+
+	s, err := inputSource.String("name")
+	expect(t, "", s)
+	expect(t, nil, err)
+	d, err := inputSource.Duration("timeout")
+	expect(t, time.Duration(0), d)
+	expect(t, nil, err)
+	i, err := inputSource.Int("db.port")
+	expect(t, 0, i)
+	expect(t, nil, err)
+}
+
+func TestMapTypeMismatchError(t *testing.T) {
+	inputSource := NewMapInputSource(
+		"test",
+		map[interface{}]interface{}{
+			"retries": "three",
+		})
+
+	_, err := inputSource.Int("retries")
+	var mismatch *TypeMismatchError
+	expect(t, true, errors.As(err, &mismatch))
+	expect(t, &TypeMismatchError{Flag: "retries", Expected: "int", Actual: "string"}, mismatch)
+}
+
+func TestMapMergeKeys(t *testing.T) {
+	defaults := map[interface{}]interface{}{
+		"timeout": "30s",
+		"retries": 3,
+	}
+	inputSource := NewMapInputSource(
+		"test",
+		map[interface{}]interface{}{
+			"defaults": defaults,
+			"staging": map[interface{}]interface{}{
+				"<<":      defaults,
+				"retries": 1,
+			},
+			"production": map[interface{}]interface{}{
+				"<<": []interface{}{
+					map[interface{}]interface{}{"timeout": "1m"},
+					defaults,
+				},
+				"replicas": 5,
+			},
+		})
+
+	d, err := inputSource.Duration("staging.timeout")
+	expect(t, 30*time.Second, d)
+	expect(t, nil, err)
+	i, err := inputSource.Int("staging.retries")
+	expect(t, 1, i)
+	expect(t, nil, err)
+
+	// the first merged map that has a key wins
+	d, err = inputSource.Duration("production.timeout")
+	expect(t, time.Minute, d)
+	expect(t, nil, err)
+	i, err = inputSource.Int("production.retries")
+	expect(t, 3, i)
+	expect(t, nil, err)
+	i, err = inputSource.Int("production.replicas")
+	expect(t, 5, i)
+	expect(t, nil, err)
+
+	expect(t, false, inputSource.isSet("production.missing"))
+}
+
+func TestMapUnknownKeys(t *testing.T) {
+	defaults := map[interface{}]interface{}{
+		"timeout": "30s",
+		"retires": 3,
+	}
+	inputSource := NewMapInputSource(
+		"test",
+		map[interface{}]interface{}{
+			"verbose": true,
+			"labels": map[interface{}]interface{}{
+				"team": "infra",
+			},
+			"db": map[interface{}]interface{}{
+				"host": "localhost",
+				"prot": 5432,
+				"pool": map[interface{}]interface{}{
+					"size": 10,
+				},
+			},
+			"server": map[interface{}]interface{}{
+				"<<": defaults,
+			},
+			"colour": "auto",
+		})
+
+	known := []string{"verbose", "labels", "db.host", "db.port", "db.pool.size", "server.timeout", "server.retries", "color"}
+	expect(t, []string{"colour", "db.prot", "server.retires"}, inputSource.UnknownKeys(known))
+
+	known = append(known, "colour", "db.prot", "server.retires")
+	expect(t, []string(nil), inputSource.UnknownKeys(known))
+}
+
+func TestMapStringSliceScalar(t *testing.T) {
+	inputSource := NewMapInputSource(
+		"test",
+		map[interface{}]interface{}{
+			"tags":   "foo",
+			"list":   []interface{}{"a", "b"},
+			"number": 5,
+			"map": map[interface{}]interface{}{
+				"a": "b",
+			},
+		})
+
+	s, err := inputSource.StringSlice("tags")
+	expect(t, []string{"foo"}, s)
+	expect(t, nil, err)
+	s, err = inputSource.StringSlice("list")
+	expect(t, []string{"a", "b"}, s)
+	expect(t, nil, err)
+	_, err = inputSource.StringSlice("number")
+	refute(t, nil, err)
+	_, err = inputSource.StringSlice("map")
 	refute(t, nil, err)
 }
@@ -42,6 +42,21 @@ func NewYamlSourceFromFlagFunc(flagFileName string) func(context *cli.Context) (
 	}
 }
 
+// NewYamlSourceFromSearchPaths creates a new Yaml InputSourceContext from
+// the first of the file names found in dirs, searched in order, e.g.
+// "config.yaml" in ".", "$XDG_CONFIG_HOME/app" and "/etc/app". Environment
+// variables in dirs are expanded and dirs referring to an unset variable are
+// skipped. An empty input source is created when no file is found.
+func NewYamlSourceFromSearchPaths(names []string, dirs []string) func(context *cli.Context) (InputSourceContext, error) {
+	return func(context *cli.Context) (InputSourceContext, error) {
+		if filePath := findConfigFile(names, dirs); filePath != "" {
+			return NewYamlSourceFromFile(filePath)
+		}
+
+		return defaultInputSource()
+	}
+}
+
 func readCommandYaml(filePath string, container interface{}) (err error) {
 	b, err := loadDataFrom(filePath)
 	if err != nil {
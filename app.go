@@ -2,12 +2,17 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"sort"
+	"strings"
+	"syscall"
+	"text/template"
 	"time"
 )
 
@@ -41,6 +46,11 @@ type App struct {
 	Commands []*Command
 	// List of flags to parse
 	Flags []Flag
+	// List of flags added to the App and to all of its commands and their
+	// subcommands, e.g. --verbose, which can be given at any level and are
+	// read from the Context of any of them. A command declaring a flag with
+	// one of the same names keeps its own flag instead
+	PersistentFlags []Flag
 	// Boolean to enable bash completion commands
 	EnableBashCompletion bool
 	// Boolean to hide built-in help command and help flag
@@ -48,24 +58,67 @@ type App struct {
 	// Boolean to hide built-in help command but keep help flag.
 	// Ignored if HideHelp is true.
 	HideHelpCommand bool
+	// The flag that prints the help, e.g. to use --usage instead of --help.
+	// It is also added to the commands and subcommands. Defaults to HelpFlag
+	HelpFlag Flag
 	// Boolean to hide built-in version flag and the VERSION section of help
 	HideVersion bool
+	// The flag that prints the version, e.g. to use --version without -v
+	// when -v is a verbose flag. Defaults to VersionFlag
+	VersionFlag Flag
+	// An action to print the version when the version flag is set, instead
+	// of the package VersionPrinter
+	VersionPrinter func(*Context)
+	// Boolean to not print usage errors, and the help shown along with them,
+	// e.g. when embedding the App. The errors are returned all the same
+	HideErrors bool
+	// Boolean to hide the environment variable and file hints of flags in
+	// the help written by the default HelpPrinter, whatever FlagStringer is
+	HideFlagHints bool
+	// Boolean to list flags in help sorted by name rather than in the order
+	// they are declared in
+	SortFlags bool
+	// Boolean to run a command given by an unambiguous prefix of the name of
+	// one of the visible commands, e.g. "stat" for "status", when no command
+	// has the exact name
+	AllowPrefixMatch bool
 	// categories contains the categorized commands and is populated on app startup
 	categories CommandCategories
 	// An action to execute when the shell completion flag is set
 	BashComplete BashCompleteFunc
 	// An action to execute before any subcommands are run, but after the context is ready
-	// If a non-nil error is returned, no subcommands are run
+	// If a non-nil error is returned, no subcommands are run. If ErrSkipAction is
+	// returned, neither subcommands nor Action are run and the App returns nil
 	Before BeforeFunc
 	// An action to execute after any subcommands are run, but after the subcommand has finished
-	// It is run even if Action() panics
+	// It is run even if Before or Action return an error or Action() panics. If
+	// After also returns an error, both errors are returned as a MultiError
 	After AfterFunc
 	// The action to execute when no subcommands are specified
 	Action ActionFunc
+	// The name of a flag of the App whose value chooses the action to run
+	// among ModeCommands when no subcommand is specified, e.g. "mode" for
+	// --mode build|test. Action is run when the flag has no value
+	ModeFlag string
+	// The commands run for each value of ModeFlag, e.g. {"build":
+	// buildCommand, "test": testCommand}, with the positional arguments of
+	// the App, from which they parse their own Flags, e.g. --mode build --
+	// --release. Other values are an error listing the allowed ones
+	ModeCommands map[string]*Command
 	// Execute this function if the proper command cannot be found
 	CommandNotFound CommandNotFoundFunc
+	// Execute this function if the proper command cannot be found to decide
+	// the error returned for it, both for help on an unknown topic and for an
+	// unknown subcommand of a Command with NoDefaultHelpOnUnknownSub. When
+	// there is neither CommandNotFound nor OnCommandNotFound, help on an
+	// unknown topic returns an ExitCoder with exit code 3.
+	OnCommandNotFound OnCommandNotFoundFunc
 	// Execute this function if a usage error occurs
 	OnUsageError OnUsageErrorFunc
+	// Execute this function on the arguments, without the program name,
+	// before they are parsed, e.g. to expand "ci" to "build test". The
+	// rewritten arguments are used to run the App and its commands
+	ArgsRewriter ArgsRewriterFunc
 	// Compilation date
 	Compiled time.Time
 	// List of all authors who contributed
@@ -74,9 +127,10 @@ type App struct {
 	Copyright string
 	// Reader reader to write input to (useful for tests)
 	Reader io.Reader
-	// Writer writer to write output to
+	// Writer writer to write output to, such as help and usage errors.
+	// Defaults to os.Stdout
 	Writer io.Writer
-	// ErrWriter writes error output
+	// ErrWriter writes error output. Defaults to os.Stderr
 	ErrWriter io.Writer
 	// ExitErrHandler processes any error encountered while running an App before
 	// it is returned to the caller. If no function is provided, HandleExitCoder
@@ -90,12 +144,68 @@ type App struct {
 	// cli.go uses text/template to render templates. You can
 	// render custom help text by setting this variable.
 	CustomAppHelpTemplate string
+	// UsageErrorMessage the text template for the message printed before
+	// the help when the arguments could not be parsed, instead of
+	// "Incorrect Usage.". The template is rendered with {{.Command}}, the
+	// full name of the command, and {{.Error}}, the parse error. Run returns
+	// the error of parsing the template if it is malformed.
+	UsageErrorMessage string
+	// Middleware wraps the action of every command, including commands with
+	// subcommands run without one and ModeCommands, in order, so that the
+	// first one is the outermost and runs first
+	Middleware []MiddlewareFunc
 	// Boolean to enable short-option handling so user can combine several
 	// single-character bool arguments into one
 	// i.e. foobar -o -v -> foobar -ov
 	UseShortOptionHandling bool
+	// Boolean to parse and validate the arguments, resolve commands and run
+	// the Before functions without calling any Action. The After functions
+	// still run, e.g. to release what the Before functions acquired.
+	DryRun bool
+	// Deprecated names of environment variables mapped to the names that
+	// replace them, or to "" if there is none. A warning is printed to the
+	// ErrWriter when the value of a flag is read from one of them, e.g. with
+	// EnvVars: []string{"NEW_VAR", "OLD_VAR"} and {"OLD_VAR": "NEW_VAR"} to
+	// keep OLD_VAR working while users move to NEW_VAR
+	DeprecatedEnvVars map[string]string
+	// HandleSignals cancels the context.Context of the Context passed to the
+	// actions when the process receives an interrupt or terminate signal,
+	// so that long running actions can shut down gracefully by selecting on
+	// its Done channel
+	HandleSignals bool
 
 	didSetup bool
+
+	// skipFlagParsing treats all arguments as normal arguments, it is set
+	// from Command.SkipFlagParsing for the apps built to run subcommands
+	skipFlagParsing bool
+
+	// errorOnUnknownCommand errors when the first argument is not a command,
+	// it is set from Command.NoDefaultHelpOnUnknownSub for the apps built to
+	// run subcommands
+	errorOnUnknownCommand bool
+
+	// rawParsing parses the arguments with the flag package alone, it is set
+	// from Command.UseRawParsing for the apps built to run subcommands
+	rawParsing bool
+
+	// hideHelpFlag leaves out the help flag but keeps the help command, it
+	// is set from Command.HideHelpFlag for the apps built to run subcommands
+	hideHelpFlag bool
+
+	// activeHelpFlag is the help flag among Flags, which lacks the names the
+	// App defines itself, or nil if there is none
+	activeHelpFlag Flag
+
+	// hideCompletionCommand leaves out the completion command, it is set for
+	// the apps built to run subcommands as completion is set up for the
+	// whole application
+	hideCompletionCommand bool
+
+	// usageErrorTemplate is UsageErrorMessage parsed on setup, or nil if it
+	// is empty or usageErrorTemplateErr is set
+	usageErrorTemplate    *template.Template
+	usageErrorTemplateErr error
 }
 
 // Tries to find out when this binary was compiled.
@@ -135,6 +245,10 @@ func (a *App) Setup() {
 
 	a.didSetup = true
 
+	if a.UsageErrorMessage != "" {
+		a.usageErrorTemplate, a.usageErrorTemplateErr = template.New("usageError").Parse(a.UsageErrorMessage)
+	}
+
 	if a.Name == "" {
 		a.Name = filepath.Base(os.Args[0])
 	}
@@ -181,22 +295,51 @@ func (a *App) Setup() {
 		if c.HelpName == "" {
 			c.HelpName = fmt.Sprintf("%s %s", a.HelpName, c.Name)
 		}
+		c.sortFlags = a.SortFlags
+		c.hideFlagHints = a.HideFlagHints
 		newCommands = append(newCommands, c)
 	}
 	a.Commands = newCommands
 
+	for _, c := range a.ModeCommands {
+		if c.HelpName == "" {
+			c.HelpName = fmt.Sprintf("%s %s", a.HelpName, c.Name)
+		}
+		c.sortFlags = a.SortFlags
+		c.hideFlagHints = a.HideFlagHints
+	}
+
+	helpFlag := a.helpFlag()
+	if hasFlag(a.Flags, helpFlag) {
+		a.activeHelpFlag = helpFlag
+	}
 	if a.Command(helpCommand.Name) == nil && !a.HideHelp {
 		if !a.HideHelpCommand {
 			a.appendCommand(helpCommand)
 		}
 
-		if HelpFlag != nil {
-			a.appendFlag(HelpFlag)
+		if helpFlag != nil && !a.hideHelpFlag && a.activeHelpFlag == nil {
+			// append help to flags, without the names the App defines itself
+			if a.activeHelpFlag = helpFlagFor(a.Flags, helpFlag); a.activeHelpFlag != nil {
+				a.appendFlag(a.activeHelpFlag)
+			}
+		}
+	}
+
+	for _, f := range a.PersistentFlags {
+		if !hasFlagName(a.Flags, f) {
+			a.appendFlag(f)
 		}
 	}
 
 	if !a.HideVersion {
-		a.appendFlag(VersionFlag)
+		a.appendFlag(a.versionFlag())
+	}
+
+	if a.EnableBashCompletion && !a.hideCompletionCommand && a.Command(completionCommand.Name) == nil {
+		c := *completionCommand
+		c.HelpName = fmt.Sprintf("%s %s", a.HelpName, c.Name)
+		a.appendCommand(&c)
 	}
 
 	a.categories = newCommandCategories()
@@ -210,6 +353,50 @@ func (a *App) Setup() {
 	}
 }
 
+// mode returns the value of the ModeFlag of the App, or "" if it has none
+func (a *App) mode(context *Context) string {
+	if a.ModeFlag == "" {
+		return ""
+	}
+	return context.String(a.ModeFlag)
+}
+
+// modeContext returns a context for running the mode command c as if its
+// name had been given as a command, with the arguments of context
+func (a *App) modeContext(context *Context, c *Command) *Context {
+	set := flag.NewFlagSet(c.Name, flag.ContinueOnError)
+	_ = set.Parse(append([]string{"--", c.Name}, context.Args().Slice()...))
+	return NewContext(a, set, context)
+}
+
+// modes returns the values of the ModeFlag of the App, sorted
+func (a *App) modes() []string {
+	var modes []string
+	for mode := range a.ModeCommands {
+		modes = append(modes, mode)
+	}
+	sort.Strings(modes)
+	return modes
+}
+
+// helpFlag returns the HelpFlag of the App, or the package HelpFlag if it
+// has none
+func (a *App) helpFlag() Flag {
+	if a.HelpFlag != nil {
+		return a.HelpFlag
+	}
+	return HelpFlag
+}
+
+// versionFlag returns the VersionFlag of the App, or the package VersionFlag
+// if it has none
+func (a *App) versionFlag() Flag {
+	if a.VersionFlag != nil {
+		return a.VersionFlag
+	}
+	return VersionFlag
+}
+
 func (a *App) newFlagSet() (*flag.FlagSet, error) {
 	return flagSet(a.Name, a.Flags)
 }
@@ -224,11 +411,34 @@ func (a *App) Run(arguments []string) (err error) {
 	return a.RunContext(context.Background(), arguments)
 }
 
+// RunCommand runs the named command with args as its arguments, as if it was
+// given on the command line without any global flags. The Before and After
+// funcs of the App and of the command are run as usual. It errors if the App
+// has no such command.
+func (a *App) RunCommand(name string, args []string) error {
+	a.Setup()
+
+	if a.Command(name) == nil {
+		return fmt.Errorf("no command named %q", name)
+	}
+
+	return a.Run(append([]string{a.Name, name}, args...))
+}
+
 // RunContext is like Run except it takes a Context that will be
 // passed to its commands and sub-commands. Through this, you can
 // propagate timeouts and cancellation requests
 func (a *App) RunContext(ctx context.Context, arguments []string) (err error) {
 	a.Setup()
+	if a.usageErrorTemplateErr != nil {
+		return a.usageErrorTemplateErr
+	}
+
+	if a.HandleSignals {
+		var stop context.CancelFunc
+		ctx, stop = signalContext(ctx)
+		defer stop()
+	}
 
 	// handle the completion flag separately from the flagset since
 	// completion could be attempted after a flag, but before its value was put
@@ -237,18 +447,35 @@ func (a *App) RunContext(ctx context.Context, arguments []string) (err error) {
 	// note that we can only do this because the shell autocomplete function
 	// always appends the completion flag at the end of the command
 	shellComplete, arguments := checkShellCompleteFlag(a, arguments)
+	var rawArgs []string
+	if len(arguments) > 0 {
+		rawArgs = append([]string{}, arguments[1:]...)
+	}
+
+	if a.ArgsRewriter != nil && len(arguments) > 0 {
+		arguments = append([]string{arguments[0]}, a.ArgsRewriter(arguments[1:])...)
+	}
 
 	set, err := a.newFlagSet()
 	if err != nil {
 		return err
 	}
 
-	err = parseIter(set, a, arguments[1:], shellComplete)
+	counts := occurrences{}
+	err = parseIter(set, a, arguments[1:], shellComplete, counts)
 	nerr := normalizeFlags(a.Flags, set)
-	context := NewContext(a, set, &Context{Context: ctx})
+	context := NewContext(a, set, nil)
+	if ctx != nil {
+		context.Context = ctx
+	}
+	context.occurrences = counts
+	context.rawArgs = rawArgs
 	if nerr != nil {
-		_, _ = fmt.Fprintln(a.Writer, nerr)
-		_ = ShowAppHelp(context)
+		nerr = &InvalidFlagsError{Command: a.Name, Err: nerr}
+		if !a.HideErrors {
+			_, _ = fmt.Fprintln(a.Writer, nerr)
+			_ = ShowAppHelp(context)
+		}
 		return nerr
 	}
 	context.shellComplete = shellComplete
@@ -263,12 +490,14 @@ func (a *App) RunContext(ctx context.Context, arguments []string) (err error) {
 			a.handleExitCoder(context, err)
 			return err
 		}
-		_, _ = fmt.Fprintf(a.Writer, "%s %s\n\n", "Incorrect Usage.", err.Error())
-		_ = ShowAppHelp(context)
+		if !a.HideErrors {
+			a.printUsageError(a.HelpName, err)
+			_ = ShowAppHelp(context)
+		}
 		return err
 	}
 
-	if !a.HideHelp && checkHelp(context) {
+	if !a.HideHelp && a.activeHelpFlag != nil && checkHelp(context, a.activeHelpFlag) {
 		_ = ShowAppHelp(context)
 		return nil
 	}
@@ -278,9 +507,13 @@ func (a *App) RunContext(ctx context.Context, arguments []string) (err error) {
 		return nil
 	}
 
+	context.warnDeprecatedFlags(a.Flags)
+
 	cerr := context.checkRequiredFlags(a.Flags)
 	if cerr != nil {
-		_ = ShowAppHelp(context)
+		if !a.HideErrors {
+			_ = ShowAppHelp(context)
+		}
 		return cerr
 	}
 
@@ -298,6 +531,9 @@ func (a *App) RunContext(ctx context.Context, arguments []string) (err error) {
 
 	if a.Before != nil {
 		beforeErr := a.Before(context)
+		if errors.Is(beforeErr, ErrSkipAction) {
+			return nil
+		}
 		if beforeErr != nil {
 			a.handleExitCoder(context, beforeErr)
 			err = beforeErr
@@ -308,12 +544,30 @@ func (a *App) RunContext(ctx context.Context, arguments []string) (err error) {
 	args := context.Args()
 	if args.Present() {
 		name := args.First()
-		c := a.Command(name)
+		c, cerr := a.resolveCommand(name)
+		if cerr != nil {
+			a.handleExitCoder(context, cerr)
+			return cerr
+		}
 		if c != nil {
 			return c.Run(context)
 		}
 	}
 
+	if a.DryRun {
+		return nil
+	}
+
+	if mode := a.mode(context); mode != "" {
+		c, ok := a.ModeCommands[mode]
+		if !ok {
+			err = fmt.Errorf("unknown %s %q, allowed values are: %s", a.ModeFlag, mode, strings.Join(a.modes(), ", "))
+			a.handleExitCoder(context, err)
+			return err
+		}
+		return c.Run(a.modeContext(context, c))
+	}
+
 	if a.Action == nil {
 		a.Action = helpCommand.Action
 	}
@@ -342,12 +596,17 @@ func (a *App) RunAndExitOnError() {
 func (a *App) RunAsSubcommand(ctx *Context) (err error) {
 	// Setup also handles HideHelp and HideHelpCommand
 	a.Setup()
+	if a.usageErrorTemplateErr != nil {
+		return a.usageErrorTemplateErr
+	}
 
 	var newCmds []*Command
 	for _, c := range a.Commands {
 		if c.HelpName == "" {
 			c.HelpName = fmt.Sprintf("%s %s", a.HelpName, c.Name)
 		}
+		c.sortFlags = a.SortFlags
+		c.hideFlagHints = a.HideFlagHints
 		newCmds = append(newCmds, c)
 	}
 	a.Commands = newCmds
@@ -357,17 +616,33 @@ func (a *App) RunAsSubcommand(ctx *Context) (err error) {
 		return err
 	}
 
-	err = parseIter(set, a, ctx.Args().Tail(), ctx.shellComplete)
+	counts := occurrences{}
+	if a.skipFlagParsing {
+		err = set.Parse(append([]string{"--"}, ctx.Args().Tail()...))
+	} else if a.rawParsing {
+		err = parseCounting(set, ctx.Args().Tail(), counts)
+		if ctx.shellComplete {
+			// the arguments may be incomplete during shell completion
+			err = nil
+		}
+	} else {
+		err = parseIter(set, a, ctx.Args().Tail(), ctx.shellComplete, counts)
+	}
 	nerr := normalizeFlags(a.Flags, set)
 	context := NewContext(a, set, ctx)
+	context.rawArgs = ctx.Args().Tail()
+	context.occurrences = counts
 
 	if nerr != nil {
-		_, _ = fmt.Fprintln(a.Writer, nerr)
-		_, _ = fmt.Fprintln(a.Writer)
-		if len(a.Commands) > 0 {
-			_ = ShowSubcommandHelp(context)
-		} else {
-			_ = ShowCommandHelp(ctx, context.Args().First())
+		nerr = &InvalidFlagsError{Command: a.Name, Err: nerr}
+		if !a.HideErrors {
+			_, _ = fmt.Fprintln(a.Writer, nerr)
+			_, _ = fmt.Fprintln(a.Writer)
+			if len(a.Commands) > 0 {
+				_ = ShowSubcommandHelp(context)
+			} else {
+				_ = ShowCommandHelp(ctx, context.Args().First())
+			}
 		}
 		return nerr
 	}
@@ -382,24 +657,34 @@ func (a *App) RunAsSubcommand(ctx *Context) (err error) {
 			a.handleExitCoder(context, err)
 			return err
 		}
-		_, _ = fmt.Fprintf(a.Writer, "%s %s\n\n", "Incorrect Usage.", err.Error())
-		_ = ShowSubcommandHelp(context)
+		if !a.HideErrors {
+			a.printUsageError(a.HelpName, err)
+			_ = ShowSubcommandHelp(context)
+		}
 		return err
 	}
 
-	if len(a.Commands) > 0 {
-		if checkSubcommandHelp(context) {
-			return nil
-		}
-	} else {
-		if checkCommandHelp(ctx, context.Args().First()) {
-			return nil
+	context.inheritPersistentFlags(a.Flags)
+
+	if a.activeHelpFlag != nil {
+		if len(a.Commands) > 0 {
+			if checkSubcommandHelp(context, a.activeHelpFlag) {
+				return nil
+			}
+		} else {
+			if checkCommandHelp(ctx, context.Args().First(), a.activeHelpFlag) {
+				return nil
+			}
 		}
 	}
 
+	context.warnDeprecatedFlags(a.Flags)
+
 	cerr := context.checkRequiredFlags(a.Flags)
 	if cerr != nil {
-		_ = ShowSubcommandHelp(context)
+		if !a.HideErrors {
+			_ = ShowSubcommandHelp(context)
+		}
 		return cerr
 	}
 
@@ -407,7 +692,7 @@ func (a *App) RunAsSubcommand(ctx *Context) (err error) {
 		defer func() {
 			afterErr := a.After(context)
 			if afterErr != nil {
-				a.handleExitCoder(context, err)
+				a.handleExitCoder(context, afterErr)
 				if err != nil {
 					err = newMultiError(err, afterErr)
 				} else {
@@ -419,6 +704,9 @@ func (a *App) RunAsSubcommand(ctx *Context) (err error) {
 
 	if a.Before != nil {
 		beforeErr := a.Before(context)
+		if errors.Is(beforeErr, ErrSkipAction) {
+			return nil
+		}
 		if beforeErr != nil {
 			a.handleExitCoder(context, beforeErr)
 			err = beforeErr
@@ -429,14 +717,28 @@ func (a *App) RunAsSubcommand(ctx *Context) (err error) {
 	args := context.Args()
 	if args.Present() {
 		name := args.First()
-		c := a.Command(name)
+		c, cerr := a.resolveCommand(name)
+		if cerr != nil {
+			a.handleExitCoder(context, cerr)
+			return cerr
+		}
 		if c != nil {
 			return c.Run(context)
 		}
+		if a.errorOnUnknownCommand {
+			if !a.HideErrors {
+				_ = ShowSubcommandHelp(context)
+			}
+			return a.commandNotFound(context, name, fmt.Errorf("unknown subcommand %q for %s", name, a.Name))
+		}
+	}
+
+	if a.DryRun {
+		return nil
 	}
 
 	// Run default Action
-	err = a.Action(context)
+	err = a.wrapAction(a.Action)(context)
 
 	a.handleExitCoder(context, err)
 	return err
@@ -453,6 +755,38 @@ func (a *App) Command(name string) *Command {
 	return nil
 }
 
+// resolveCommand returns the command named name. When there is none and
+// AllowPrefixMatch is set, it returns the only visible command with a name
+// starting with name, or errors if several commands do.
+func (a *App) resolveCommand(name string) (*Command, error) {
+	if c := a.Command(name); c != nil || !a.AllowPrefixMatch || name == "" {
+		return c, nil
+	}
+
+	var matches []*Command
+	for _, c := range a.VisibleCommands() {
+		for _, n := range c.Names() {
+			if strings.HasPrefix(n, name) {
+				matches = append(matches, c)
+				break
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	}
+
+	var candidates []string
+	for _, c := range matches {
+		candidates = append(candidates, c.Name)
+	}
+	return nil, fmt.Errorf("command %q is ambiguous, could be one of: %s", name, strings.Join(candidates, ", "))
+}
+
 // VisibleCategories returns a slice of categories and commands that are
 // Hidden=false
 func (a *App) VisibleCategories() []CommandCategory {
@@ -483,7 +817,17 @@ func (a *App) VisibleCommands() []*Command {
 
 // VisibleFlags returns a slice of the Flags with Hidden=false
 func (a *App) VisibleFlags() []Flag {
-	return visibleFlags(a.Flags)
+	flags := visibleFlags(a.Flags)
+	if a.SortFlags {
+		sort.Stable(FlagsByName(flags))
+	}
+	return flags
+}
+
+// VisibleFlagCategories returns the VisibleFlags grouped by their Category,
+// or nil if none of them has a category
+func (a *App) VisibleFlagCategories() []VisibleFlagCategory {
+	return visibleFlagCategories(a.VisibleFlags())
 }
 
 func (a *App) appendFlag(fl Flag) {
@@ -498,6 +842,19 @@ func (a *App) appendCommand(c *Command) {
 	}
 }
 
+// commandNotFound runs CommandNotFound and OnCommandNotFound for the unknown
+// command and returns the error to return for it, which is err unless
+// OnCommandNotFound replaces it
+func (a *App) commandNotFound(context *Context, command string, err error) error {
+	if a.CommandNotFound != nil {
+		a.CommandNotFound(context, command)
+	}
+	if a.OnCommandNotFound != nil {
+		return a.OnCommandNotFound(context, command, err)
+	}
+	return err
+}
+
 func (a *App) handleExitCoder(context *Context, err error) {
 	if a.ExitErrHandler != nil {
 		a.ExitErrHandler(context, err)
@@ -506,6 +863,29 @@ func (a *App) handleExitCoder(context *Context, err error) {
 	}
 }
 
+// wrapAction returns action wrapped by the Middleware of the App
+func (a *App) wrapAction(action ActionFunc) ActionFunc {
+	for i := len(a.Middleware) - 1; i >= 0; i-- {
+		action = a.Middleware[i](action)
+	}
+	return action
+}
+
+// printUsageError prints the message for a parse error of the command
+// called name, rendered from UsageErrorMessage if set
+func (a *App) printUsageError(name string, err error) {
+	if a.usageErrorTemplate == nil {
+		_, _ = fmt.Fprintf(a.Writer, "%s %s\n\n", "Incorrect Usage.", err.Error())
+		return
+	}
+
+	_ = a.usageErrorTemplate.Execute(a.Writer, struct {
+		Command string
+		Error   error
+	}{name, err})
+	_, _ = fmt.Fprint(a.Writer, "\n\n")
+}
+
 // Author represents someone who has contributed to a cli project.
 type Author struct {
 	Name  string // The Authors name
@@ -538,3 +918,41 @@ func HandleAction(action interface{}, context *Context) (err error) {
 
 	return errInvalidActionType
 }
+
+// NewActionFunc adapts action to an ActionFunc for the Action of an App or
+// a Command, using HandleAction, so that actions with the legacy
+// func(*Context) signature, which return nil, can be used along with those
+// returning an error. The returned ActionFunc panics with the message of
+// the invalid Action type error when it is run if action is not an
+// ActionFunc, a func(*Context) error or a func(*Context).
+func NewActionFunc(action interface{}) ActionFunc {
+	return func(c *Context) error {
+		err := HandleAction(action, c)
+		if err == errInvalidActionType {
+			panic(fmt.Sprintf("%s (got %T)", err, action))
+		}
+		return err
+	}
+}
+
+// signalContext returns a copy of parent that is cancelled when the process
+// receives an interrupt or terminate signal. The returned stop function stops
+// the signal handling and cancels the context.
+func signalContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
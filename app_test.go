@@ -2,6 +2,7 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"flag"
 	"fmt"
@@ -9,8 +10,10 @@ import (
 	"io/ioutil"
 	"os"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 var (
@@ -352,6 +355,38 @@ func ExampleApp_Run_bashComplete() {
 	// h
 }
 
+func ExampleApp_Run_bashComplete_flagValue() {
+	// set args for examples sake
+	os.Args = []string{"greet", "deploy", "--region", "--generate-bash-completion"}
+
+	app := &App{
+		Name:                 "greet",
+		EnableBashCompletion: true,
+		Commands: []*Command{
+			{
+				Name: "deploy",
+				Flags: []Flag{
+					&StringFlag{
+						Name: "region",
+						CompletionFunc: func(c *Context) []string {
+							return []string{"eu-west-1", "us-east-1"}
+						},
+					},
+				},
+				Action: func(c *Context) error {
+					fmt.Printf("deploying to %s", c.String("region"))
+					return nil
+				},
+			},
+		},
+	}
+
+	_ = app.Run(os.Args)
+	// Output:
+	// eu-west-1
+	// us-east-1
+}
+
 func ExampleApp_Run_zshComplete() {
 	// set args for examples sake
 	os.Args = []string{"greet", "--generate-bash-completion"}
@@ -444,6 +479,318 @@ func TestApp_Setup_defaultsWriter(t *testing.T) {
 	expect(t, app.Writer, os.Stdout)
 }
 
+func TestApp_RunCommand(t *testing.T) {
+	var actions []string
+	record := func(name string) func(*Context) error {
+		return func(*Context) error {
+			actions = append(actions, name)
+			return nil
+		}
+	}
+
+	var region string
+	app := &App{
+		Writer: ioutil.Discard,
+		Before: record("app-before"),
+		After:  record("app-after"),
+		Commands: []*Command{{
+			Name:    "deploy",
+			Aliases: []string{"d"},
+			Before:  record("deploy-before"),
+			After:   record("deploy-after"),
+			Flags:   []Flag{&StringFlag{Name: "region"}},
+			Action: func(c *Context) error {
+				region = c.String("region")
+				return record("deploy")(c)
+			},
+		}},
+	}
+
+	err := app.RunCommand("d", []string{"--region", "eu"})
+	expect(t, err, nil)
+	expect(t, region, "eu")
+	expect(t, actions, []string{"app-before", "deploy-before", "deploy", "deploy-after", "app-after"})
+
+	actions = nil
+	err = app.RunCommand("destroy", nil)
+	expect(t, err, errors.New(`no command named "destroy"`))
+	expect(t, actions, []string(nil))
+}
+
+func TestApp_DeprecatedFlag(t *testing.T) {
+	var name string
+	var errBuf bytes.Buffer
+	app := &App{
+		Writer:    ioutil.Discard,
+		ErrWriter: &errBuf,
+		Flags: []Flag{
+			&StringFlag{Name: "old", Aliases: []string{"o"}, Deprecated: "use --new"},
+			&StringFlag{Name: "new"},
+		},
+		Commands: []*Command{
+			{
+				Name: "cmd",
+				Flags: []Flag{
+					&BoolFlag{Name: "legacy", Deprecated: "it is the default now"},
+				},
+				Action: func(*Context) error { return nil },
+			},
+		},
+		Action: func(ctx *Context) error {
+			name = ctx.String("old")
+			return nil
+		},
+	}
+
+	err := app.Run([]string{"run", "-o", "value"})
+	expect(t, err, nil)
+	expect(t, name, "value")
+	expect(t, errBuf.String(), "flag --old is deprecated: use --new\n")
+
+	errBuf.Reset()
+	err = app.Run([]string{"run", "--new", "value", "cmd", "--legacy"})
+	expect(t, err, nil)
+	expect(t, errBuf.String(), "flag --legacy is deprecated: it is the default now\n")
+
+	errBuf.Reset()
+	app.Flags = append(app.Flags, &deprecatedFlag{&IntFlag{Name: "retries"}})
+	err = app.Run([]string{"run", "--retries", "3"})
+	expect(t, err, nil)
+	expect(t, errBuf.String(), "flag --retries is deprecated: retries are unlimited\n")
+}
+
+// deprecatedFlag is a user-defined flag that is always deprecated
+type deprecatedFlag struct {
+	Flag
+}
+
+func (f *deprecatedFlag) GetDeprecated() string {
+	return "retries are unlimited"
+}
+
+func TestApp_DeprecatedEnvVars(t *testing.T) {
+	defer resetEnv(os.Environ())
+
+	tests := []struct {
+		env      map[string]string
+		args     []string
+		expected string
+		warning  string
+	}{
+		{env: map[string]string{"NEW_VAR": "new"}, expected: "new"},
+		{env: map[string]string{"OLD_VAR": "old"}, expected: "old", warning: "environment variable OLD_VAR is deprecated, use NEW_VAR instead\n"},
+		{env: map[string]string{"NEW_VAR": "new", "OLD_VAR": "old"}, expected: "new"},
+		{env: map[string]string{"OLDER_VAR": "older"}, expected: "older", warning: "environment variable OLDER_VAR is deprecated\n"},
+		{env: map[string]string{"OLD_VAR": "old"}, args: []string{"--name", "flag"}, expected: "flag"},
+	}
+
+	for _, test := range tests {
+		os.Clearenv()
+		for k, v := range test.env {
+			_ = os.Setenv(k, v)
+		}
+
+		var name string
+		var errBuf bytes.Buffer
+		app := &App{
+			Writer:            ioutil.Discard,
+			ErrWriter:         &errBuf,
+			DeprecatedEnvVars: map[string]string{"OLD_VAR": "NEW_VAR", "OLDER_VAR": ""},
+			Flags: []Flag{
+				&StringFlag{Name: "name", EnvVars: []string{"NEW_VAR", "OLD_VAR", "OLDER_VAR"}},
+			},
+			Action: func(ctx *Context) error {
+				name = ctx.String("name")
+				return nil
+			},
+		}
+
+		err := app.Run(append([]string{"run"}, test.args...))
+		expect(t, err, nil)
+		expect(t, name, test.expected)
+		expect(t, errBuf.String(), test.warning)
+	}
+}
+
+func TestApp_AllowPrefixMatch(t *testing.T) {
+	var ran string
+	record := func(name string) ActionFunc {
+		return func(*Context) error {
+			ran = name
+			return nil
+		}
+	}
+
+	newApp := func(allow bool) *App {
+		return &App{
+			Writer:           ioutil.Discard,
+			AllowPrefixMatch: allow,
+			Action:           record("app"),
+			Commands: []*Command{
+				{Name: "status", Action: record("status")},
+				{Name: "stash", Action: record("stash")},
+				{Name: "secret", Hidden: true, Action: record("secret")},
+				{
+					Name:    "remote",
+					Aliases: []string{"rem"},
+					Subcommands: []*Command{
+						{Name: "add", Action: record("remote add")},
+						{Name: "remove", Action: record("remote remove")},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{args: []string{"stat"}, want: "status"},
+		{args: []string{"stas"}, want: "stash"},
+		{args: []string{"status"}, want: "status"},
+		{args: []string{"rem", "ad"}, want: "remote add"},
+		{args: []string{"st"}, wantErr: `command "st" is ambiguous, could be one of: status, stash`},
+		{args: []string{"remote", "re"}, want: "remote remove"},
+		{args: []string{"s"}, wantErr: `command "s" is ambiguous, could be one of: status, stash`},
+		{args: []string{"sec"}, want: "app"},
+	}
+
+	for _, test := range tests {
+		ran = ""
+		err := newApp(true).Run(append([]string{"git"}, test.args...))
+		if test.wantErr != "" {
+			if err == nil {
+				t.Fatalf("expected an error for %v", test.args)
+			}
+			expect(t, err.Error(), test.wantErr)
+			continue
+		}
+		expect(t, err, nil)
+		expect(t, ran, test.want)
+	}
+
+	ran = ""
+	err := newApp(false).Run([]string{"git", "stat"})
+	expect(t, err, nil)
+	expect(t, ran, "app")
+}
+
+func TestApp_ArgsRewriter(t *testing.T) {
+	var ran []string
+	var verbose bool
+	record := func(name string) ActionFunc {
+		return func(ctx *Context) error {
+			ran = append(ran, name+" "+strings.Join(ctx.Args().Slice(), " "))
+			verbose = ctx.Bool("verbose")
+			return nil
+		}
+	}
+
+	aliases := map[string][]string{
+		"ci": {"build", "test", "--verbose"},
+		"up": {"deploy"},
+	}
+
+	newApp := func() *App {
+		return &App{
+			Writer: ioutil.Discard,
+			ArgsRewriter: func(args []string) []string {
+				if len(args) > 0 {
+					if expanded, ok := aliases[args[0]]; ok {
+						return append(append([]string{}, expanded...), args[1:]...)
+					}
+				}
+				return args
+			},
+			Commands: []*Command{
+				{
+					Name: "build",
+					Subcommands: []*Command{
+						{
+							Name:   "test",
+							Flags:  []Flag{&BoolFlag{Name: "verbose"}},
+							Action: record("build test"),
+						},
+					},
+				},
+				{Name: "deploy", Action: record("deploy")},
+			},
+		}
+	}
+
+	err := newApp().Run([]string{"app", "ci", "unit"})
+	expect(t, err, nil)
+	expect(t, ran, []string{"build test unit"})
+	expect(t, verbose, true)
+
+	ran = nil
+	err = newApp().Run([]string{"app", "up", "prod"})
+	expect(t, err, nil)
+	expect(t, ran, []string{"deploy prod"})
+}
+
+func TestApp_DryRun(t *testing.T) {
+	var actions []string
+	record := func(name string) func(*Context) error {
+		return func(*Context) error {
+			actions = append(actions, name)
+			return nil
+		}
+	}
+
+	newApp := func() *App {
+		return &App{
+			DryRun: true,
+			Writer: ioutil.Discard,
+			Action: record("app"),
+			Before: record("app-before"),
+			After:  record("app-after"),
+			Commands: []*Command{
+				{
+					Name:   "deploy",
+					Action: record("deploy"),
+					Flags: []Flag{
+						&StringFlag{Name: "region", Required: true},
+					},
+				},
+				{
+					Name:   "db",
+					Before: record("db-before"),
+					Subcommands: []*Command{
+						{
+							Name:   "migrate",
+							Before: record("migrate-before"),
+							After:  record("migrate-after"),
+							Action: record("migrate"),
+						},
+					},
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		args            []string
+		expectedActions []string
+		expectErr       bool
+	}{
+		{args: []string{"app"}, expectedActions: []string{"app-before", "app-after"}},
+		{args: []string{"app", "deploy", "--region", "eu"}, expectedActions: []string{"app-before", "app-after"}},
+		{args: []string{"app", "deploy"}, expectedActions: []string{"app-before", "app-after"}, expectErr: true},
+		{args: []string{"app", "deploy", "--bogus"}, expectedActions: []string{"app-before", "app-after"}, expectErr: true},
+		{args: []string{"app", "db", "migrate"}, expectedActions: []string{"app-before", "db-before", "migrate-before", "migrate-after", "app-after"}},
+	}
+
+	for _, c := range cases {
+		actions = nil
+		err := newApp().Run(c.args)
+		expect(t, err != nil, c.expectErr)
+		expect(t, actions, c.expectedActions)
+	}
+}
+
 func TestApp_RunAsSubcommandParseFlags(t *testing.T) {
 	var context *Context
 
@@ -645,7 +992,7 @@ func TestApp_UseShortOptionHandling_missing_value(t *testing.T) {
 	}
 
 	err := app.Run([]string{"", "-n"})
-	expect(t, err, errors.New("flag needs an argument: -n"))
+	expect(t, err, &MissingValueError{Flag: "n"})
 }
 
 func TestApp_UseShortOptionHandlingCommand(t *testing.T) {
@@ -689,7 +1036,7 @@ func TestApp_UseShortOptionHandlingCommand_missing_value(t *testing.T) {
 	app.Commands = []*Command{command}
 
 	err := app.Run([]string{"", "cmd", "-n"})
-	expect(t, err, errors.New("flag needs an argument: -n"))
+	expect(t, err, &MissingValueError{Flag: "n"})
 }
 
 func TestApp_UseShortOptionHandlingSubCommand(t *testing.T) {
@@ -742,7 +1089,7 @@ func TestApp_UseShortOptionHandlingSubCommand_missing_value(t *testing.T) {
 	app.Commands = []*Command{command}
 
 	err := app.Run([]string{"", "cmd", "sub", "-n"})
-	expect(t, err, errors.New("flag needs an argument: -n"))
+	expect(t, err, &MissingValueError{Flag: "n"})
 }
 
 func TestApp_Float64Flag(t *testing.T) {
@@ -1039,6 +1386,56 @@ func TestApp_BeforeFunc(t *testing.T) {
 	}
 }
 
+func TestApp_BeforeFuncSkipAction(t *testing.T) {
+	var actionCalled, subCalled, afterCalled bool
+	app := &App{
+		Before: func(c *Context) error {
+			if c.Bool("skip") {
+				return ErrSkipAction
+			}
+			return nil
+		},
+		After: func(c *Context) error {
+			afterCalled = true
+			return nil
+		},
+		Action: func(c *Context) error {
+			actionCalled = true
+			return nil
+		},
+		Commands: []*Command{
+			{
+				Name: "sub",
+				Before: func(c *Context) error {
+					return fmt.Errorf("nothing to do: %w", ErrSkipAction)
+				},
+				Action: func(c *Context) error {
+					subCalled = true
+					return nil
+				},
+			},
+		},
+		Flags: []Flag{
+			&BoolFlag{Name: "skip"},
+		},
+		Writer:    ioutil.Discard,
+		ErrWriter: ioutil.Discard,
+	}
+
+	err := app.Run([]string{"command", "--skip"})
+	expect(t, err, nil)
+	expect(t, actionCalled, false)
+	expect(t, afterCalled, true)
+
+	err = app.Run([]string{"command", "--skip", "sub"})
+	expect(t, err, nil)
+	expect(t, subCalled, false)
+
+	err = app.Run([]string{"command", "sub"})
+	expect(t, err, nil)
+	expect(t, subCalled, false)
+}
+
 func TestApp_AfterFunc(t *testing.T) {
 	counts := &opCounts{}
 	afterError := fmt.Errorf("fail")
@@ -1299,6 +1696,186 @@ func TestApp_VersionPrinter(t *testing.T) {
 	}
 }
 
+func TestApp_Version(t *testing.T) {
+	var buf bytes.Buffer
+	ran := false
+	app := &App{
+		Name:    "greet",
+		Version: "1.2.3",
+		Writer:  &buf,
+		Action: func(*Context) error {
+			ran = true
+			return nil
+		},
+	}
+
+	err := app.Run([]string{"greet", "-v"})
+	expect(t, err, nil)
+	expect(t, ran, false)
+	expect(t, buf.String(), "greet version 1.2.3\n")
+}
+
+func TestApp_VersionPrinterAndFlagOfApp(t *testing.T) {
+	var buf bytes.Buffer
+	verbose := false
+	app := &App{
+		Name:        "greet",
+		Version:     "1.2.3",
+		Writer:      &buf,
+		VersionFlag: &BoolFlag{Name: "version"},
+		VersionPrinter: func(c *Context) {
+			_, _ = fmt.Fprintf(c.App.Writer, "%s %s (commit abc123)\n", c.App.Name, c.App.Version)
+		},
+		Flags: []Flag{
+			&BoolFlag{Name: "verbose", Aliases: []string{"v"}},
+		},
+		Action: func(c *Context) error {
+			verbose = c.Bool("verbose")
+			return nil
+		},
+	}
+
+	err := app.Run([]string{"greet", "-v"})
+	expect(t, err, nil)
+	expect(t, verbose, true)
+	expect(t, buf.String(), "")
+
+	err = app.Run([]string{"greet", "--version"})
+	expect(t, err, nil)
+	expect(t, buf.String(), "greet 1.2.3 (commit abc123)\n")
+}
+
+func TestApp_HelpFlagOfApp(t *testing.T) {
+	var buf bytes.Buffer
+	ran := false
+	app := &App{
+		Name:     "greet",
+		Writer:   &buf,
+		HelpFlag: &BoolFlag{Name: "usage", Aliases: []string{"u"}},
+		Commands: []*Command{
+			{
+				Name:  "hello",
+				Usage: "says hello",
+				Flags: []Flag{
+					&BoolFlag{Name: "help", Aliases: []string{"h"}},
+				},
+				Action: func(c *Context) error {
+					ran = true
+					return nil
+				},
+			},
+		},
+	}
+
+	err := app.Run([]string{"greet", "--usage"})
+	expect(t, err, nil)
+	expect(t, strings.Contains(buf.String(), "--usage, -u"), true)
+	expect(t, strings.Contains(buf.String(), "--help"), false)
+
+	buf.Reset()
+	err = app.Run([]string{"greet", "hello", "-u"})
+	expect(t, err, nil)
+	expect(t, ran, false)
+	expect(t, strings.Contains(buf.String(), "says hello"), true)
+
+	// --help is free for the command to use
+	err = app.Run([]string{"greet", "hello", "--help"})
+	expect(t, err, nil)
+	expect(t, ran, true)
+}
+
+func TestNewActionFunc(t *testing.T) {
+	legacyRan := false
+	app := &App{
+		Commands: []*Command{
+			{
+				Name: "legacy",
+				Action: NewActionFunc(func(c *Context) {
+					legacyRan = true
+				}),
+			},
+			{
+				Name: "failing",
+				Action: NewActionFunc(func(c *Context) error {
+					return errors.New("failed")
+				}),
+			},
+			{
+				Name:   "invalid",
+				Action: NewActionFunc(func() {}),
+			},
+		},
+	}
+
+	err := app.Run([]string{"app", "legacy"})
+	expect(t, err, nil)
+	expect(t, legacyRan, true)
+
+	err = app.Run([]string{"app", "failing"})
+	expect(t, err, errors.New("failed"))
+
+	defer func() {
+		r := recover()
+		msg, ok := r.(string)
+		expect(t, ok, true)
+		expect(t, strings.HasPrefix(msg, "ERROR invalid Action type."), true)
+		expect(t, strings.HasSuffix(msg, "(got func())"), true)
+	}()
+	_ = app.Run([]string{"app", "invalid"})
+	t.Error("expected a panic for an invalid action type")
+}
+
+func TestApp_ModeFlag(t *testing.T) {
+	var ran []string
+	newApp := func() *App {
+		return &App{
+			Flags: []Flag{
+				&StringFlag{Name: "mode", Aliases: []string{"m"}},
+			},
+			ModeFlag: "mode",
+			ModeCommands: map[string]*Command{
+				"build": {
+					Name:  "build",
+					Flags: []Flag{&BoolFlag{Name: "release"}},
+					Before: func(c *Context) error {
+						ran = append(ran, "before build")
+						return nil
+					},
+					Action: func(c *Context) error {
+						ran = append(ran, fmt.Sprintf("build %s release=%t", c.Args().First(), c.Bool("release")))
+						return nil
+					},
+				},
+				"test": {
+					Name: "test",
+					Action: func(c *Context) error {
+						ran = append(ran, c.Command.Name)
+						return nil
+					},
+				},
+			},
+			Action: func(c *Context) error {
+				ran = append(ran, "default")
+				return nil
+			},
+		}
+	}
+
+	expect(t, newApp().Run([]string{"app", "--mode", "build", "./..."}), nil)
+	expect(t, newApp().Run([]string{"app", "-m", "test"}), nil)
+	expect(t, newApp().Run([]string{"app"}), nil)
+	expect(t, ran, []string{"before build", "build ./... release=false", "test", "default"})
+
+	// the mode command parses its own flags, given after "--" so that the
+	// App does not parse them
+	ran = nil
+	expect(t, newApp().Run([]string{"app", "--mode", "build", "--", "--release", "./..."}), nil)
+	expect(t, ran, []string{"before build", "build ./... release=true"})
+
+	err := newApp().Run([]string{"app", "--mode", "deploy"})
+	expect(t, err.Error(), `unknown mode "deploy", allowed values are: build, test`)
+}
+
 func TestApp_CommandNotFound(t *testing.T) {
 	counts := &opCounts{}
 	app := &App{
@@ -1325,6 +1902,82 @@ func TestApp_CommandNotFound(t *testing.T) {
 	expect(t, counts.Total, 1)
 }
 
+func TestApp_OnCommandNotFound(t *testing.T) {
+	notFound := errors.New("unknown command")
+
+	tests := []struct {
+		name              string
+		commandNotFound   bool
+		onCommandNotFound OnCommandNotFoundFunc
+		wantErr           string
+		wantCode          int
+		wantCalled        bool
+	}{
+		{
+			name:     "default",
+			wantErr:  "No help topic for 'foo'",
+			wantCode: 3,
+		},
+		{
+			name:            "CommandNotFound only",
+			commandNotFound: true,
+			wantCalled:      true,
+		},
+		{
+			name:            "OnCommandNotFound replaces the error",
+			commandNotFound: true,
+			onCommandNotFound: func(c *Context, command string, err error) error {
+				expect(t, command, "foo")
+				expect(t, err, nil)
+				return Exit(notFound, 2)
+			},
+			wantErr:    "unknown command",
+			wantCode:   2,
+			wantCalled: true,
+		},
+		{
+			name: "OnCommandNotFound ignores the error",
+			onCommandNotFound: func(c *Context, command string, err error) error {
+				if err == nil {
+					t.Error("expected the default error")
+				}
+				return nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			called := false
+			var handled error
+			app := &App{
+				Writer:            ioutil.Discard,
+				OnCommandNotFound: test.onCommandNotFound,
+				ExitErrHandler: func(c *Context, err error) {
+					handled = err
+				},
+				Commands: []*Command{{Name: "bar"}},
+			}
+			if test.commandNotFound {
+				app.CommandNotFound = func(c *Context, command string) {
+					called = true
+				}
+			}
+
+			err := app.Run([]string{"command", "foo"})
+			expect(t, called, test.wantCalled)
+			if test.wantErr == "" {
+				expect(t, err, nil)
+				return
+			}
+
+			expect(t, err.Error(), test.wantErr)
+			expect(t, err.(ExitCoder).ExitCode(), test.wantCode)
+			expect(t, handled, err)
+		})
+	}
+}
+
 func TestApp_OrderOfOperations(t *testing.T) {
 	counts := &opCounts{}
 
@@ -1555,6 +2208,72 @@ func TestApp_Run_SubcommandFullPath(t *testing.T) {
 	}
 }
 
+func TestApp_Run_HelpThreeLevelsDeep(t *testing.T) {
+	newApp := func(w io.Writer, leaf *Command) *App {
+		return &App{
+			Name:   "mycli",
+			Writer: w,
+			Commands: []*Command{{
+				Name:  "a",
+				Usage: "does a things",
+				Subcommands: []*Command{{
+					Name:        "b",
+					Usage:       "does b things",
+					Subcommands: []*Command{leaf},
+				}},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name string
+		leaf *Command
+		args []string
+		want string
+	}{
+		{
+			name: "leaf",
+			leaf: &Command{Name: "c", Usage: "does c things"},
+			args: []string{"mycli", "a", "b", "c", "--help"},
+			want: "mycli a b c - does c things",
+		},
+		{
+			name: "leaf with short flag",
+			leaf: &Command{Name: "c", Usage: "does c things"},
+			args: []string{"mycli", "a", "b", "c", "-h"},
+			want: "mycli a b c - does c things",
+		},
+		{
+			name: "with subcommands",
+			leaf: &Command{
+				Name:        "c",
+				Usage:       "does c things",
+				Subcommands: []*Command{{Name: "d", Usage: "does d things"}},
+			},
+			args: []string{"mycli", "a", "b", "c", "--help"},
+			want: "mycli a b c - does c things",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			err := newApp(buf, test.leaf).Run(test.args)
+			expect(t, err, nil)
+
+			output := buf.String()
+			if !strings.Contains(output, test.want) {
+				t.Errorf("expected %q in output: %s", test.want, output)
+			}
+			for _, other := range []string{"does a things", "does b things"} {
+				if strings.Contains(output, other) {
+					t.Errorf("expected help for c only, got %q in output: %s", other, output)
+				}
+			}
+		})
+	}
+}
+
 func TestApp_Run_SubcommandHelpName(t *testing.T) {
 	app := &App{}
 	buf := new(bytes.Buffer)
@@ -1940,6 +2659,48 @@ func TestApp_Run_SubcommandDoesNotOverwriteErrorFromBefore(t *testing.T) {
 	}
 }
 
+func TestApp_IncorrectUsageWrittenToWriter(t *testing.T) {
+	newApp := func(w io.Writer) *App {
+		return &App{
+			Writer: w,
+			Flags: []Flag{
+				&IntFlag{Name: "flag"},
+			},
+			Commands: []*Command{
+				{
+					Name:  "bar",
+					Flags: []Flag{&IntFlag{Name: "count"}},
+				},
+				{
+					Name: "baz",
+					Subcommands: []*Command{
+						{Name: "qux"},
+					},
+					Flags: []Flag{&IntFlag{Name: "count"}},
+				},
+			},
+		}
+	}
+
+	for _, test := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"foo", "--flag=wrong"}, "Incorrect Usage. invalid value \"wrong\" for flag -flag"},
+		{[]string{"foo", "bar", "--count=wrong"}, "Incorrect Usage. invalid value \"wrong\" for flag -count"},
+		{[]string{"foo", "baz", "--count=wrong"}, "Incorrect Usage. invalid value \"wrong\" for flag -count"},
+	} {
+		var buf bytes.Buffer
+		err := newApp(&buf).Run(test.args)
+		if err == nil {
+			t.Fatalf("expected an error for %v", test.args)
+		}
+		if !strings.HasPrefix(buf.String(), test.expected) {
+			t.Errorf("expected output for %v to start with %q, got %q", test.args, test.expected, buf.String())
+		}
+	}
+}
+
 func TestApp_OnUsageError_WithWrongFlagValue(t *testing.T) {
 	app := &App{
 		Flags: []Flag{
@@ -2217,6 +2978,43 @@ func TestWhenExitSubCommandWithCodeThenAppQuitUnexpectedly(t *testing.T) {
 	}
 }
 
+func TestHandleExitCoder_AfterErrorReachesExitErrHandler(t *testing.T) {
+	testCode := 7
+
+	app := newTestApp()
+	app.Commands = []*Command{
+		{
+			Name: "cmd",
+			Subcommands: []*Command{
+				{
+					Name:   "subcmd",
+					Action: func(c *Context) error { return nil },
+					After: func(c *Context) error {
+						return Exit("after error", testCode)
+					},
+				},
+			},
+		},
+	}
+
+	var exitCodeFromExitErrHandler int
+	app.ExitErrHandler = func(c *Context, err error) {
+		if exitErr, ok := err.(ExitCoder); ok {
+			exitCodeFromExitErrHandler = exitErr.ExitCode()
+		}
+	}
+
+	err := app.Run([]string{"myapp", "cmd", "subcmd"})
+
+	if err == nil {
+		t.Fatal("expected the After error to be returned")
+	}
+
+	if exitCodeFromExitErrHandler != testCode {
+		t.Errorf("exitCodeFromExitErrHandler should be %v, but its value is %v", testCode, exitCodeFromExitErrHandler)
+	}
+}
+
 func newTestApp() *App {
 	a := NewApp()
 	a.Writer = ioutil.Discard
@@ -2253,3 +3051,83 @@ func TestSetupInitializesOnlyNilWriters(t *testing.T) {
 		t.Errorf("expected a.Writer to be os.Stdout")
 	}
 }
+
+func TestApp_RunContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var observed error
+	app := &App{
+		Commands: []*Command{
+			{
+				Name: "serve",
+				Action: func(c *Context) error {
+					cancel()
+					select {
+					case <-c.Done():
+						observed = c.Err()
+					case <-time.After(time.Second):
+					}
+					return nil
+				},
+			},
+		},
+	}
+
+	expect(t, app.RunContext(ctx, []string{"app", "serve"}), nil)
+	expect(t, observed, context.Canceled)
+}
+
+func TestApp_HandleSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending an interrupt is not supported on windows")
+	}
+
+	var observed error
+	app := &App{
+		HandleSignals: true,
+		Action: func(c *Context) error {
+			p, err := os.FindProcess(os.Getpid())
+			if err != nil {
+				return err
+			}
+			if err := p.Signal(os.Interrupt); err != nil {
+				return err
+			}
+			select {
+			case <-c.Done():
+				observed = c.Err()
+			case <-time.After(5 * time.Second):
+			}
+			return nil
+		},
+	}
+
+	expect(t, app.Run([]string{"app"}), nil)
+	expect(t, observed, context.Canceled)
+}
+
+func TestApp_HelpFlagWithOwnH(t *testing.T) {
+	var host string
+	buf := new(bytes.Buffer)
+	app := &App{
+		Name:   "mycli",
+		Writer: buf,
+		Flags: []Flag{
+			&StringFlag{Name: "host", Aliases: []string{"h"}},
+		},
+		Action: func(c *Context) error {
+			host = c.String("h")
+			return nil
+		},
+	}
+
+	err := app.Run([]string{"mycli", "-h", "example.com"})
+	expect(t, err, nil)
+	expect(t, host, "example.com")
+	expect(t, buf.String(), "")
+
+	err = app.Run([]string{"mycli", "--help"})
+	expect(t, err, nil)
+	if !strings.Contains(buf.String(), "--help ") || strings.Contains(buf.String(), "--help, -h") {
+		t.Errorf("expected --help without -h in output: %s", buf.String())
+	}
+}
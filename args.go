@@ -19,7 +19,7 @@ type Args interface {
 type args []string
 
 func (a *args) Get(n int) string {
-	if len(*a) > n {
+	if n >= 0 && len(*a) > n {
 		return (*a)[n]
 	}
 	return ""
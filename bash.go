@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"text/template"
+)
+
+// ToBashCompletion creates a bash completion string for the `*App`, which
+// completes through the --generate-bash-completion flag of the App.
+// The function errors if either parsing or writing of the string fails.
+func (a *App) ToBashCompletion() (string, error) {
+	var w bytes.Buffer
+	if err := a.writeBashCompletionTemplate(&w); err != nil {
+		return "", err
+	}
+	return w.String(), nil
+}
+
+type bashCompletionTemplate struct {
+	App *App
+}
+
+func (a *App) writeBashCompletionTemplate(w io.Writer) error {
+	const name = "cli"
+	t, err := template.New(name).Parse(BashCompletionTemplate)
+	if err != nil {
+		return err
+	}
+
+	return t.ExecuteTemplate(w, name, &bashCompletionTemplate{App: a})
+}
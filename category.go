@@ -1,5 +1,7 @@
 package cli
 
+import "sort"
+
 // CommandCategories interface allows for category manipulation
 type CommandCategories interface {
 	// AddCommand adds a command to a category, creating a new category if necessary.
@@ -77,3 +79,68 @@ func (c *commandCategory) VisibleCommands() []*Command {
 	}
 	return ret
 }
+
+// VisibleFlagCategory is a category containing the visible flags of an App
+// or Command that have the same Category, for grouping flags in help.
+type VisibleFlagCategory interface {
+	// Name returns the category name string, which is empty for the flags
+	// without a category
+	Name() string
+	// Flags returns the flags of the category in the order they are defined
+	Flags() []Flag
+}
+
+type visibleFlagCategory struct {
+	name  string
+	flags []Flag
+}
+
+func (c *visibleFlagCategory) Name() string {
+	return c.name
+}
+
+func (c *visibleFlagCategory) Flags() []Flag {
+	return c.flags
+}
+
+// visibleFlagCategories groups the visible flags fl by their Category.
+// The flags without a category come first, followed by the categories in
+// lexicographic order. It returns nil when none of the flags has a category,
+// so that help without categories is left as is.
+func visibleFlagCategories(fl []Flag) []VisibleFlagCategory {
+	var categories []*visibleFlagCategory
+	categorized := false
+	for _, f := range fl {
+		name := flagCategory(f)
+		if name != "" {
+			categorized = true
+		}
+
+		var category *visibleFlagCategory
+		for _, c := range categories {
+			if c.name == name {
+				category = c
+				break
+			}
+		}
+		if category == nil {
+			category = &visibleFlagCategory{name: name}
+			categories = append(categories, category)
+		}
+		category.flags = append(category.flags, f)
+	}
+
+	if !categorized {
+		return nil
+	}
+
+	sort.SliceStable(categories, func(i, j int) bool {
+		return lexicographicLess(categories[i].name, categories[j].name)
+	})
+
+	ret := make([]VisibleFlagCategory, len(categories))
+	for i, c := range categories {
+		ret[i] = c
+	}
+	return ret
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"sort"
@@ -21,15 +22,23 @@ type Command struct {
 	Description string
 	// A short description of the arguments of this command
 	ArgsUsage string
+	// The minimum number of positional arguments of this command
+	MinArgs int
+	// The maximum number of positional arguments of this command, 0 for no
+	// limit. Set MinArgs and MaxArgs to the same number to require exactly
+	// that many arguments
+	MaxArgs int
 	// The category the command is part of
 	Category string
 	// The function to call when checking for bash command completions
 	BashComplete BashCompleteFunc
 	// An action to execute before any sub-subcommands are run, but after the context is ready
-	// If a non-nil error is returned, no sub-subcommands are run
+	// If a non-nil error is returned, no sub-subcommands are run. If ErrSkipAction
+	// is returned, neither sub-subcommands nor Action are run and nil is returned
 	Before BeforeFunc
 	// An action to execute after any subcommands are run, but after the subcommand has finished
-	// It is run even if Action() panics
+	// It is run even if Before or Action return an error or Action() panics. If
+	// After also returns an error, both errors are returned as a MultiError
 	After AfterFunc
 	// The function to call when this command is invoked
 	Action ActionFunc
@@ -37,17 +46,44 @@ type Command struct {
 	OnUsageError OnUsageErrorFunc
 	// List of child commands
 	Subcommands []*Command
+	// Boolean to return an error when the first argument is not one of the
+	// Subcommands, instead of running the Action, e.g. so that scripts fail
+	// on a mistyped subcommand. The help of the subcommands is shown unless
+	// HideErrors is set
+	NoDefaultHelpOnUnknownSub bool
 	// List of flags to parse
 	Flags []Flag
 	// Treat all flags as normal arguments if true
 	SkipFlagParsing bool
+	// Boolean to parse the flags of the command and collect the flags it does
+	// not define, with their values, in Context.PassthroughArgs instead of
+	// failing on them, e.g. for a command wrapping another program. Ignored
+	// for a command with Subcommands or SkipFlagParsing
+	PassThroughUnknownFlags bool
+	// Boolean to parse the arguments with the flag package alone, which stops
+	// at the first positional argument and leaves everything after it
+	// untouched. This is predictable, at the cost of combined short options
+	// and of negative numbers as positional arguments, which are taken for
+	// flags. Unknown flags are reported in the terse form of the flag package
+	UseRawParsing bool
 	// Boolean to hide built-in help command and help flag
 	HideHelp bool
 	// Boolean to hide built-in help command but keep help flag
 	// Ignored if HideHelp is true.
 	HideHelpCommand bool
+	// Boolean to hide built-in help flag but keep help command, e.g. when
+	// the command uses -h for something else. Ignored if HideHelp is true.
+	HideHelpFlag bool
 	// Boolean to hide this command from help or completion
 	Hidden bool
+	// Boolean to ask the user to confirm with Context.Confirm before the
+	// Action is run, e.g. for destructive commands. ConfirmFlag is added to
+	// the flags of the command to skip the prompt. Declining returns an
+	// ExitCoder with exit code 1.
+	RequireConfirmation bool
+	// Name of a bool flag of the command, e.g. "force", that skips the
+	// prompt of RequireConfirmation like ConfirmFlag when it is set
+	ConfirmSkipFlag string
 	// Boolean to enable short-option handling so user can combine several
 	// single-character bool arguments into one
 	// i.e. foobar -o -v -> foobar -ov
@@ -59,8 +95,21 @@ type Command struct {
 
 	// CustomHelpTemplate the text template for the command help topic.
 	// cli.go uses text/template to render templates. You can
-	// render custom help text by setting this variable.
+	// render custom help text by setting this variable. The template is
+	// rendered with the Command, or with the App running its Subcommands
+	// for a command that has some, and {{.HelpData}} gives the HelpData of
+	// either. CommandHelpTemplate is used when it is empty.
 	CustomHelpTemplate string
+
+	// sortFlags lists the flags in help sorted by name, it is set from
+	// App.SortFlags on app setup
+	sortFlags bool
+	// hideFlagHints leaves the environment variable and file hints of flags
+	// out of help, it is set from App.HideFlagHints on app setup
+	hideFlagHints bool
+	// activeHelpFlag is the help flag among Flags, which lacks the names
+	// the command defines itself, or nil if there is none
+	activeHelpFlag Flag
 }
 
 type Commands []*Command
@@ -94,19 +143,46 @@ func (c *Command) Run(ctx *Context) (err error) {
 		return c.startApp(ctx)
 	}
 
-	if !c.HideHelp && HelpFlag != nil {
-		// append help to flags
-		c.appendFlag(HelpFlag)
+	if helpFlag := ctx.App.helpFlag(); hasFlag(c.Flags, helpFlag) {
+		c.activeHelpFlag = helpFlag
+	} else if !c.HideHelp && !c.HideHelpFlag && helpFlag != nil && c.activeHelpFlag == nil {
+		// append help to flags, without the names the command defines itself
+		if c.activeHelpFlag = helpFlagFor(c.Flags, helpFlag); c.activeHelpFlag != nil {
+			c.appendFlag(c.activeHelpFlag)
+		}
+	}
+
+	for _, f := range ctx.App.PersistentFlags {
+		if !hasFlagName(c.Flags, f) {
+			c.appendFlag(f)
+		}
+	}
+
+	if c.RequireConfirmation && ConfirmFlag != nil && !hasFlagName(c.Flags, ConfirmFlag) {
+		c.appendFlag(ConfirmFlag)
 	}
 
 	if ctx.App.UseShortOptionHandling {
 		c.UseShortOptionHandling = true
 	}
 
-	set, err := c.parseFlags(ctx.Args(), ctx.shellComplete)
+	cmdArgs := ctx.Args()
+	var passthroughArgs []string
+	if c.PassThroughUnknownFlags && !c.SkipFlagParsing {
+		var known []string
+		known, passthroughArgs = splitUnknownFlags(c.Flags, cmdArgs.Tail())
+		knownArgs := args(append([]string{cmdArgs.First()}, known...))
+		cmdArgs = &knownArgs
+	}
+
+	counts := occurrences{}
+	set, err := c.parseFlags(cmdArgs, ctx.shellComplete, counts)
 
 	context := NewContext(ctx.App, set, ctx)
 	context.Command = c
+	context.passthroughArgs = passthroughArgs
+	context.rawArgs = ctx.Args().Tail()
+	context.occurrences = counts
 	if checkCommandCompletions(context, c.Name) {
 		return nil
 	}
@@ -117,27 +193,41 @@ func (c *Command) Run(ctx *Context) (err error) {
 			context.App.handleExitCoder(context, err)
 			return err
 		}
-		_, _ = fmt.Fprintln(context.App.Writer, "Incorrect Usage:", err.Error())
-		_, _ = fmt.Fprintln(context.App.Writer)
-		_ = ShowCommandHelp(context, c.Name)
+		if !context.App.HideErrors {
+			context.App.printUsageError(c.HelpName, err)
+			_ = ShowCommandHelp(context, c.Name)
+		}
 		return err
 	}
 
-	if checkCommandHelp(context, c.Name) {
+	context.inheritPersistentFlags(c.Flags)
+
+	if c.activeHelpFlag != nil && checkCommandHelp(context, c.Name, c.activeHelpFlag) {
 		return nil
 	}
 
+	context.warnDeprecatedFlags(c.Flags)
+
 	cerr := context.checkRequiredFlags(c.Flags)
 	if cerr != nil {
-		_ = ShowCommandHelp(context, c.Name)
+		if !context.App.HideErrors {
+			_ = ShowCommandHelp(context, c.Name)
+		}
 		return cerr
 	}
 
+	if aerr := c.checkArgsCount(context.Args()); aerr != nil {
+		if !context.App.HideErrors {
+			_ = ShowCommandHelp(context, c.Name)
+		}
+		return aerr
+	}
+
 	if c.After != nil {
 		defer func() {
 			afterErr := c.After(context)
 			if afterErr != nil {
-				context.App.handleExitCoder(context, err)
+				context.App.handleExitCoder(context, afterErr)
 				if err != nil {
 					err = newMultiError(err, afterErr)
 				} else {
@@ -149,18 +239,36 @@ func (c *Command) Run(ctx *Context) (err error) {
 
 	if c.Before != nil {
 		err = c.Before(context)
+		if errors.Is(err, ErrSkipAction) {
+			return nil
+		}
 		if err != nil {
 			context.App.handleExitCoder(context, err)
 			return err
 		}
 	}
 
+	if ctx.App.DryRun {
+		return nil
+	}
+
 	if c.Action == nil {
 		c.Action = helpSubcommand.Action
 	}
 
+	if c.RequireConfirmation && !c.confirmationSkipped(context) {
+		confirmed, cerr := context.Confirm(fmt.Sprintf("Are you sure you want to run %q?", c.FullName()))
+		if cerr == nil && !confirmed {
+			cerr = Exit("aborted", 1)
+		}
+		if cerr != nil {
+			context.App.handleExitCoder(context, cerr)
+			return cerr
+		}
+	}
+
 	context.Command = c
-	err = c.Action(context)
+	err = context.App.wrapAction(c.Action)(context)
 
 	if err != nil {
 		context.App.handleExitCoder(context, err)
@@ -168,6 +276,41 @@ func (c *Command) Run(ctx *Context) (err error) {
 	return err
 }
 
+// confirmationSkipped returns whether ConfirmFlag or ConfirmSkipFlag was set
+// on the command to run it without asking for confirmation
+func (c *Command) confirmationSkipped(ctx *Context) bool {
+	if ConfirmFlag != nil {
+		for _, name := range ConfirmFlag.Names() {
+			if lookupBool(name, ctx.flagSet) {
+				return true
+			}
+		}
+	}
+	return c.ConfirmSkipFlag != "" && lookupBool(c.ConfirmSkipFlag, ctx.flagSet)
+}
+
+// checkArgsCount errors if the number of args is outside of the bounds set
+// by MinArgs and MaxArgs
+func (c *Command) checkArgsCount(args Args) error {
+	n := args.Len()
+	switch {
+	case c.MinArgs > 0 && c.MinArgs == c.MaxArgs && n != c.MinArgs:
+		return fmt.Errorf("command %s requires exactly %d %s, got %d", c.Name, c.MinArgs, pluralArgs(c.MinArgs), n)
+	case n < c.MinArgs:
+		return fmt.Errorf("command %s requires at least %d %s, got %d", c.Name, c.MinArgs, pluralArgs(c.MinArgs), n)
+	case c.MaxArgs > 0 && n > c.MaxArgs:
+		return fmt.Errorf("command %s accepts at most %d %s, got %d", c.Name, c.MaxArgs, pluralArgs(c.MaxArgs), n)
+	}
+	return nil
+}
+
+func pluralArgs(n int) string {
+	if n == 1 {
+		return "argument"
+	}
+	return "arguments"
+}
+
 func (c *Command) newFlagSet() (*flag.FlagSet, error) {
 	return flagSet(c.Name, c.Flags)
 }
@@ -176,7 +319,7 @@ func (c *Command) useShortOptionHandling() bool {
 	return c.UseShortOptionHandling
 }
 
-func (c *Command) parseFlags(args Args, shellComplete bool) (*flag.FlagSet, error) {
+func (c *Command) parseFlags(args Args, shellComplete bool, counts occurrences) (*flag.FlagSet, error) {
 	set, err := c.newFlagSet()
 	if err != nil {
 		return nil, err
@@ -186,14 +329,22 @@ func (c *Command) parseFlags(args Args, shellComplete bool) (*flag.FlagSet, erro
 		return set, set.Parse(append([]string{"--"}, args.Tail()...))
 	}
 
-	err = parseIter(set, c, args.Tail(), shellComplete)
+	if c.UseRawParsing {
+		err = parseCounting(set, args.Tail(), counts)
+		if shellComplete {
+			// the arguments may be incomplete during shell completion
+			err = nil
+		}
+	} else {
+		err = parseIter(set, c, args.Tail(), shellComplete, counts)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	err = normalizeFlags(c.Flags, set)
 	if err != nil {
-		return nil, err
+		return nil, &InvalidFlagsError{Command: c.Name, Err: err}
 	}
 
 	return set, nil
@@ -215,9 +366,61 @@ func (c *Command) HasName(name string) bool {
 }
 
 func (c *Command) startApp(ctx *Context) error {
+	return subAppFrom(ctx.App, c).RunAsSubcommand(ctx)
+}
+
+// subAppFrom returns the App that runs the subcommands of c. Settings that
+// apply to the whole application are inherited from parent; everything else
+// comes from the command itself.
+func subAppFrom(parent *App, c *Command) *App {
 	app := &App{
-		Metadata: ctx.App.Metadata,
-		Name:     fmt.Sprintf("%s %s", ctx.App.Name, c.Name),
+		// inherited from the parent app
+		Metadata:               parent.Metadata,
+		Version:                parent.Version,
+		HideVersion:            true,
+		Compiled:               parent.Compiled,
+		Authors:                parent.Authors,
+		Copyright:              parent.Copyright,
+		ExtraInfo:              parent.ExtraInfo,
+		Reader:                 parent.Reader,
+		Writer:                 parent.Writer,
+		ErrWriter:              parent.ErrWriter,
+		ExitErrHandler:         parent.ExitErrHandler,
+		CommandNotFound:        parent.CommandNotFound,
+		OnCommandNotFound:      parent.OnCommandNotFound,
+		EnableBashCompletion:   parent.EnableBashCompletion,
+		UseShortOptionHandling: parent.UseShortOptionHandling,
+		DryRun:                 parent.DryRun,
+		HideFlagHints:          parent.HideFlagHints,
+		HideErrors:             parent.HideErrors,
+		SortFlags:              parent.SortFlags,
+		AllowPrefixMatch:       parent.AllowPrefixMatch,
+		DeprecatedEnvVars:      parent.DeprecatedEnvVars,
+		HelpFlag:               parent.HelpFlag,
+		PersistentFlags:        parent.PersistentFlags,
+		UsageErrorMessage:      parent.UsageErrorMessage,
+		Middleware:             parent.Middleware,
+
+		// taken from the command
+		Name:                  fmt.Sprintf("%s %s", parent.Name, c.Name),
+		Usage:                 c.Usage,
+		UsageText:             c.UsageText,
+		Description:           c.Description,
+		ArgsUsage:             c.ArgsUsage,
+		CustomAppHelpTemplate: c.CustomHelpTemplate,
+		Commands:              c.Subcommands,
+		Flags:                 c.Flags,
+		HideHelp:              c.HideHelp,
+		HideHelpCommand:       c.HideHelpCommand,
+		hideHelpFlag:          c.HideHelpFlag,
+		Before:                c.Before,
+		After:                 c.After,
+		Action:                c.Action,
+		OnUsageError:          c.OnUsageError,
+		skipFlagParsing:       c.SkipFlagParsing,
+		rawParsing:            c.UseRawParsing,
+		errorOnUnknownCommand: c.NoDefaultHelpOnUnknownSub,
+		hideCompletionCommand: true,
 	}
 
 	if c.HelpName == "" {
@@ -226,29 +429,13 @@ func (c *Command) startApp(ctx *Context) error {
 		app.HelpName = app.Name
 	}
 
-	app.Usage = c.Usage
-	app.UsageText = c.UsageText
-	app.Description = c.Description
-	app.ArgsUsage = c.ArgsUsage
-
-	// set CommandNotFound
-	app.CommandNotFound = ctx.App.CommandNotFound
-	app.CustomAppHelpTemplate = c.CustomHelpTemplate
-
-	// set the flags and commands
-	app.Commands = c.Subcommands
-	app.Flags = c.Flags
-	app.HideHelp = c.HideHelp
-	app.HideHelpCommand = c.HideHelpCommand
+	if c.BashComplete != nil {
+		app.BashComplete = c.BashComplete
+	}
 
-	app.Version = ctx.App.Version
-	app.HideVersion = true
-	app.Compiled = ctx.App.Compiled
-	app.Reader = ctx.App.Reader
-	app.Writer = ctx.App.Writer
-	app.ErrWriter = ctx.App.ErrWriter
-	app.ExitErrHandler = ctx.App.ExitErrHandler
-	app.UseShortOptionHandling = ctx.App.UseShortOptionHandling
+	if app.Action == nil {
+		app.Action = helpSubcommand.Action
+	}
 
 	app.categories = newCommandCategories()
 	for _, command := range c.Subcommands {
@@ -257,32 +444,26 @@ func (c *Command) startApp(ctx *Context) error {
 
 	sort.Sort(app.categories.(*commandCategories))
 
-	// bash completion
-	app.EnableBashCompletion = ctx.App.EnableBashCompletion
-	if c.BashComplete != nil {
-		app.BashComplete = c.BashComplete
-	}
-
-	// set the actions
-	app.Before = c.Before
-	app.After = c.After
-	if c.Action != nil {
-		app.Action = c.Action
-	} else {
-		app.Action = helpSubcommand.Action
-	}
-	app.OnUsageError = c.OnUsageError
-
 	for index, cc := range app.Commands {
 		app.Commands[index].commandNamePath = []string{c.Name, cc.Name}
 	}
 
-	return app.RunAsSubcommand(ctx)
+	return app
 }
 
 // VisibleFlags returns a slice of the Flags with Hidden=false
 func (c *Command) VisibleFlags() []Flag {
-	return visibleFlags(c.Flags)
+	flags := visibleFlags(c.Flags)
+	if c.sortFlags {
+		sort.Stable(FlagsByName(flags))
+	}
+	return flags
+}
+
+// VisibleFlagCategories returns the VisibleFlags grouped by their Category,
+// or nil if none of them has a category
+func (c *Command) VisibleFlagCategories() []VisibleFlagCategory {
+	return visibleFlagCategories(c.VisibleFlags())
 }
 
 func (c *Command) appendFlag(fl Flag) {
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 )
@@ -18,7 +20,7 @@ func TestCommandFlagParsing(t *testing.T) {
 		expectedErr            error
 	}{
 		// Test normal "not ignoring flags" flow
-		{testArgs: []string{"test-cmd", "-break", "blah", "blah"}, skipFlagParsing: false, useShortOptionHandling: false, expectedErr: errors.New("flag provided but not defined: -break")},
+		{testArgs: []string{"test-cmd", "-break", "blah", "blah"}, skipFlagParsing: false, useShortOptionHandling: false, expectedErr: &UnknownFlagError{Flag: "-break", Command: "test-cmd"}},
 		{testArgs: []string{"test-cmd", "blah", "blah"}, skipFlagParsing: true, useShortOptionHandling: false, expectedErr: nil},   // Test SkipFlagParsing without any args that look like flags
 		{testArgs: []string{"test-cmd", "blah", "-break"}, skipFlagParsing: true, useShortOptionHandling: false, expectedErr: nil}, // Test SkipFlagParsing with random flag arg
 		{testArgs: []string{"test-cmd", "blah", "-help"}, skipFlagParsing: true, useShortOptionHandling: false, expectedErr: nil},  // Test SkipFlagParsing with "special" help flag arg
@@ -61,17 +63,17 @@ func TestParseAndRunShortOpts(t *testing.T) {
 		{testArgs: args{"foo", "test", "-af"}, expectedErr: nil, expectedArgs: &args{}},
 		{testArgs: args{"foo", "test", "-cf"}, expectedErr: nil, expectedArgs: &args{}},
 		{testArgs: args{"foo", "test", "-acf"}, expectedErr: nil, expectedArgs: &args{}},
-		{testArgs: args{"foo", "test", "--acf"}, expectedErr: errors.New("flag provided but not defined: -acf"), expectedArgs: nil},
-		{testArgs: args{"foo", "test", "-invalid"}, expectedErr: errors.New("flag provided but not defined: -invalid"), expectedArgs: nil},
-		{testArgs: args{"foo", "test", "-acf", "-invalid"}, expectedErr: errors.New("flag provided but not defined: -invalid"), expectedArgs: nil},
-		{testArgs: args{"foo", "test", "--invalid"}, expectedErr: errors.New("flag provided but not defined: -invalid"), expectedArgs: nil},
-		{testArgs: args{"foo", "test", "-acf", "--invalid"}, expectedErr: errors.New("flag provided but not defined: -invalid"), expectedArgs: nil},
+		{testArgs: args{"foo", "test", "--acf"}, expectedErr: &UnknownFlagError{Flag: "--acf", Command: "test"}, expectedArgs: nil},
+		{testArgs: args{"foo", "test", "-invalid"}, expectedErr: &UnknownFlagError{Flag: "-invalid", Command: "test"}, expectedArgs: nil},
+		{testArgs: args{"foo", "test", "-acf", "-invalid"}, expectedErr: &UnknownFlagError{Flag: "-invalid", Command: "test"}, expectedArgs: nil},
+		{testArgs: args{"foo", "test", "--invalid"}, expectedErr: &UnknownFlagError{Flag: "--invalid", Command: "test"}, expectedArgs: nil},
+		{testArgs: args{"foo", "test", "-acf", "--invalid"}, expectedErr: &UnknownFlagError{Flag: "--invalid", Command: "test"}, expectedArgs: nil},
 		{testArgs: args{"foo", "test", "-acf", "arg1", "-invalid"}, expectedErr: nil, expectedArgs: &args{"arg1", "-invalid"}},
 		{testArgs: args{"foo", "test", "-acf", "arg1", "--invalid"}, expectedErr: nil, expectedArgs: &args{"arg1", "--invalid"}},
 		{testArgs: args{"foo", "test", "-acfi", "not-arg", "arg1", "-invalid"}, expectedErr: nil, expectedArgs: &args{"arg1", "-invalid"}},
 		{testArgs: args{"foo", "test", "-i", "ivalue"}, expectedErr: nil, expectedArgs: &args{}},
 		{testArgs: args{"foo", "test", "-i", "ivalue", "arg1"}, expectedErr: nil, expectedArgs: &args{"arg1"}},
-		{testArgs: args{"foo", "test", "-i"}, expectedErr: errors.New("flag needs an argument: -i"), expectedArgs: nil},
+		{testArgs: args{"foo", "test", "-i"}, expectedErr: &MissingValueError{Flag: "i"}, expectedArgs: nil},
 	}
 
 	for _, c := range cases {
@@ -132,6 +134,82 @@ func TestCommand_Run_DoesNotOverwriteErrorFromBefore(t *testing.T) {
 	}
 }
 
+func TestCommand_Run_AfterAlwaysRuns(t *testing.T) {
+	cases := []struct {
+		name           string
+		beforeErr      error
+		actionErr      error
+		afterErr       error
+		expectAction   bool
+		expectedErrors []string
+	}{
+		{
+			name:           "before error skips action",
+			beforeErr:      errors.New("before error"),
+			expectedErrors: []string{"before error"},
+		},
+		{
+			name:           "action error is returned",
+			actionErr:      errors.New("action error"),
+			expectAction:   true,
+			expectedErrors: []string{"action error"},
+		},
+		{
+			name:           "action and after errors are both returned",
+			actionErr:      errors.New("action error"),
+			afterErr:       errors.New("after error"),
+			expectAction:   true,
+			expectedErrors: []string{"action error", "after error"},
+		},
+		{
+			name:         "no errors",
+			expectAction: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actionCalled, afterCalled := false, false
+			app := &App{
+				Commands: []*Command{
+					{
+						Name: "bar",
+						Before: func(*Context) error {
+							return c.beforeErr
+						},
+						Action: func(*Context) error {
+							actionCalled = true
+							return c.actionErr
+						},
+						After: func(*Context) error {
+							afterCalled = true
+							return c.afterErr
+						},
+					},
+				},
+				Writer: ioutil.Discard,
+			}
+
+			err := app.Run([]string{"foo", "bar"})
+
+			expect(t, actionCalled, c.expectAction)
+			expect(t, afterCalled, true)
+			if len(c.expectedErrors) == 0 {
+				expect(t, err, nil)
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error from Run, got none")
+			}
+			for _, msg := range c.expectedErrors {
+				if !strings.Contains(err.Error(), msg) {
+					t.Errorf("expected %q in error %q", msg, err)
+				}
+			}
+		})
+	}
+}
+
 func TestCommand_Run_BeforeSavesMetadata(t *testing.T) {
 	var receivedMsgFromAction string
 	var receivedMsgFromAfter string
@@ -234,6 +312,56 @@ func TestCommand_OnUsageError_WithWrongFlagValue(t *testing.T) {
 	}
 }
 
+func TestCommand_UsageErrorMessage(t *testing.T) {
+	var buf bytes.Buffer
+	app := &App{
+		Name:              "foo",
+		Writer:            &buf,
+		UsageErrorMessage: "Utilisation incorrecte de {{.Command}} : {{.Error}}",
+		Commands: []*Command{
+			{
+				Name:  "bar",
+				Flags: []Flag{&IntFlag{Name: "flag"}},
+			},
+		},
+	}
+
+	err := app.Run([]string{"foo", "bar", "--flag=wrong"})
+	if err == nil {
+		t.Fatalf("expected to receive error from Run, got none")
+	}
+
+	want := "Utilisation incorrecte de foo bar : " + err.Error() + "\n\n"
+	if !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("expected output to start with %q, got %q", want, buf.String())
+	}
+
+	buf.Reset()
+	_ = app.Run([]string{"foo", "--nope"})
+	if !strings.HasPrefix(buf.String(), "Utilisation incorrecte de foo : ") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestCommand_UsageErrorMessageMalformed(t *testing.T) {
+	app := &App{
+		Name:              "foo",
+		Writer:            ioutil.Discard,
+		UsageErrorMessage: "{{.Command",
+		Commands: []*Command{
+			{
+				Name:  "bar",
+				Flags: []Flag{&IntFlag{Name: "flag"}},
+			},
+		},
+	}
+
+	err := app.Run([]string{"foo", "bar", "--flag=wrong"})
+	if err == nil || !strings.Contains(err.Error(), "usageError") {
+		t.Errorf("expected the error of parsing UsageErrorMessage, got %v", err)
+	}
+}
+
 func TestCommand_OnUsageError_WithSubcommand(t *testing.T) {
 	app := &App{
 		Commands: []*Command{
@@ -332,6 +460,158 @@ func TestCommandSkipFlagParsing(t *testing.T) {
 	}
 }
 
+func TestCommandSkipFlagParsingWithSubcommands(t *testing.T) {
+	cases := []struct {
+		testArgs     args
+		expectedArgs *args
+		expectedSub  bool
+	}{
+		{testArgs: args{"some-exec", "some-command", "--unknown", "foo"}, expectedArgs: &args{"--unknown", "foo"}},
+		{testArgs: args{"some-exec", "some-command", "some-arg", "--flag=foo", "-x"}, expectedArgs: &args{"some-arg", "--flag=foo", "-x"}},
+		{testArgs: args{"some-exec", "some-command", "sub", "--unknown"}, expectedArgs: &args{"--unknown"}, expectedSub: true},
+	}
+
+	for _, c := range cases {
+		var args Args
+		subCalled := false
+		app := &App{
+			Commands: []*Command{
+				{
+					SkipFlagParsing: true,
+					Name:            "some-command",
+					Flags: []Flag{
+						&StringFlag{Name: "flag"},
+					},
+					Action: func(c *Context) error {
+						args = c.Args()
+						return nil
+					},
+					Subcommands: []*Command{
+						{
+							Name:            "sub",
+							SkipFlagParsing: true,
+							Action: func(c *Context) error {
+								subCalled = true
+								args = c.Args()
+								return nil
+							},
+						},
+					},
+				},
+			},
+			Writer: ioutil.Discard,
+		}
+
+		err := app.Run(c.testArgs)
+		expect(t, err, nil)
+		expect(t, args, c.expectedArgs)
+		expect(t, subCalled, c.expectedSub)
+	}
+}
+
+func TestCommand_Run_RequiredFlagMissing(t *testing.T) {
+	var outputBuffer bytes.Buffer
+	called := false
+	app := &App{
+		Writer: &outputBuffer,
+		Commands: []*Command{
+			{
+				Name: "deploy",
+				Flags: []Flag{
+					&StringFlag{Name: "region", Required: true},
+					&StringFlag{Name: "n", Required: true},
+				},
+				Action: func(c *Context) error {
+					called = true
+					return nil
+				},
+			},
+		},
+	}
+
+	err := app.Run([]string{"foo", "deploy"})
+
+	expect(t, called, false)
+	if err == nil {
+		t.Fatal("expected an error for the missing required flags")
+	}
+	expect(t, err.Error(), `Required flags "region, n" not set`)
+	if !strings.Contains(outputBuffer.String(), "deploy [command options]") {
+		t.Errorf("expected the command help to be shown, got %q", outputBuffer.String())
+	}
+}
+
+func TestCommand_Run_ValueFlagSpellings(t *testing.T) {
+	cases := []struct {
+		testArgs     args
+		expectedOut  string
+		expectedArgs args
+	}{
+		{testArgs: args{"foo", "test", "--out=foo.txt", "input"}, expectedOut: "foo.txt", expectedArgs: args{"input"}},
+		{testArgs: args{"foo", "test", "--out", "foo.txt", "input"}, expectedOut: "foo.txt", expectedArgs: args{"input"}},
+		{testArgs: args{"foo", "test", "--force", "--out=foo.txt", "input"}, expectedOut: "foo.txt", expectedArgs: args{"input"}},
+		{testArgs: args{"foo", "test", "--out", "foo.txt", "--force", "input"}, expectedOut: "foo.txt", expectedArgs: args{"input"}},
+		{testArgs: args{"foo", "test", "-o=foo.txt", "input"}, expectedOut: "foo.txt", expectedArgs: args{"input"}},
+	}
+
+	for _, c := range cases {
+		var out string
+		var cmdArgs Args
+		app := &App{
+			Commands: []*Command{
+				{
+					Name: "test",
+					Flags: []Flag{
+						&StringFlag{Name: "out", Aliases: []string{"o"}},
+						&BoolFlag{Name: "force"},
+					},
+					Action: func(c *Context) error {
+						out = c.String("out")
+						cmdArgs = c.Args()
+						return nil
+					},
+				},
+			},
+			Writer: ioutil.Discard,
+		}
+
+		err := app.Run(c.testArgs)
+		expect(t, err, nil)
+		expect(t, out, c.expectedOut)
+		expect(t, cmdArgs, &c.expectedArgs)
+	}
+}
+
+func TestCommand_MultipleAliases(t *testing.T) {
+	cmd := &Command{Name: "remove", Aliases: []string{"rm", "del"}, Usage: "remove a thing"}
+
+	for _, name := range []string{"remove", "rm", "del"} {
+		expect(t, cmd.HasName(name), true)
+	}
+	expect(t, cmd.HasName("delete"), false)
+
+	var outputBuffer bytes.Buffer
+	called := ""
+	cmd.Action = func(c *Context) error {
+		called = c.Command.Name
+		return nil
+	}
+	app := &App{
+		Writer:   &outputBuffer,
+		Commands: []*Command{cmd},
+	}
+
+	err := app.Run([]string{"foo", "del"})
+	expect(t, err, nil)
+	expect(t, called, "remove")
+
+	err = app.Run([]string{"foo", "help"})
+	expect(t, err, nil)
+	if !strings.Contains(outputBuffer.String(), "remove, rm, del  remove a thing") {
+		t.Errorf("expected aliases in help output, got %q", outputBuffer.String())
+	}
+}
+
 func TestCommand_Run_CustomShellCompleteAcceptsMalformedFlags(t *testing.T) {
 	cases := []struct {
 		testArgs    args
@@ -422,3 +702,715 @@ func TestCommand_CanAddVFlagOnCommands(t *testing.T) {
 	err := app.Run([]string{"foo", "bar"})
 	expect(t, err, nil)
 }
+
+func TestCommand_Run_SubcommandAppInheritsParentSettings(t *testing.T) {
+	var subApp *App
+	app := &App{
+		Name:                 "mycli",
+		EnableBashCompletion: true,
+		Copyright:            "(c) the authors",
+		Authors:              []*Author{{Name: "someone"}},
+		Writer:               ioutil.Discard,
+		Commands: []*Command{{
+			Name: "remote",
+			Subcommands: []*Command{{
+				Name: "add",
+				Action: func(c *Context) error {
+					subApp = c.App
+					return nil
+				},
+			}},
+		}},
+	}
+
+	err := app.Run([]string{"mycli", "remote", "add"})
+	expect(t, err, nil)
+
+	expect(t, subApp.Name, "mycli remote")
+	expect(t, subApp.EnableBashCompletion, true)
+	expect(t, subApp.Copyright, "(c) the authors")
+	expect(t, subApp.Authors, app.Authors)
+	expect(t, subApp.HideVersion, true)
+}
+
+func TestCommand_Run_HideHelpFlagWithOwnH(t *testing.T) {
+	for _, hideHelpFlag := range []bool{true, false} {
+		var host string
+		buf := new(bytes.Buffer)
+		app := &App{
+			Name:   "mycli",
+			Writer: buf,
+			Commands: []*Command{{
+				Name:         "connect",
+				Usage:        "connects to a host",
+				HideHelpFlag: hideHelpFlag,
+				Flags: []Flag{
+					&StringFlag{Name: "host", Aliases: []string{"h"}},
+				},
+				Action: func(c *Context) error {
+					host = c.String("h")
+					return nil
+				},
+			}},
+		}
+
+		err := app.Run([]string{"mycli", "connect", "-h", "example.com"})
+		expect(t, err, nil)
+		expect(t, host, "example.com")
+		expect(t, buf.String(), "")
+
+		// the help command is still available
+		err = app.Run([]string{"mycli", "help", "connect"})
+		expect(t, err, nil)
+		if !strings.Contains(buf.String(), "connects to a host") {
+			t.Errorf("expected help for connect in output: %s", buf.String())
+		}
+		if got := strings.Contains(buf.String(), "--help"); got == hideHelpFlag {
+			t.Errorf("expected help flag shown %v in output: %s", !hideHelpFlag, buf.String())
+		}
+		if strings.Contains(buf.String(), "--help, -h") {
+			t.Errorf("expected -h to be left to the command in output: %s", buf.String())
+		}
+
+		// --help works without the -h the command defines
+		buf.Reset()
+		host = ""
+		err = app.Run([]string{"mycli", "connect", "--help"})
+		if hideHelpFlag {
+			if err == nil {
+				t.Error("expected an error for --help without a help flag")
+			}
+			continue
+		}
+		expect(t, err, nil)
+		expect(t, host, "")
+		if !strings.Contains(buf.String(), "connects to a host") {
+			t.Errorf("expected help for connect in output: %s", buf.String())
+		}
+	}
+}
+
+func TestCommand_Run_HideHelpFlag(t *testing.T) {
+	buf := new(bytes.Buffer)
+	app := &App{
+		Name:   "mycli",
+		Writer: buf,
+		Commands: []*Command{{
+			Name:         "remote",
+			Usage:        "manages remotes",
+			HideHelpFlag: true,
+			Subcommands: []*Command{{
+				Name:   "add",
+				Action: func(*Context) error { return nil },
+			}},
+		}},
+	}
+
+	err := app.Run([]string{"mycli", "remote", "help"})
+	expect(t, err, nil)
+
+	output := buf.String()
+	if !strings.Contains(output, "manages remotes") {
+		t.Errorf("expected help for remote in output: %s", output)
+	}
+	if strings.Contains(output, "--help") {
+		t.Errorf("expected no help flag in output: %s", output)
+	}
+}
+
+func TestCommand_Run_DuplicateFlagNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags []Flag
+		dup   string
+	}{
+		{
+			name:  "name",
+			flags: []Flag{&BoolFlag{Name: "verbose"}, &BoolFlag{Name: "verbose"}},
+			dup:   "verbose",
+		},
+		{
+			name:  "alias",
+			flags: []Flag{&BoolFlag{Name: "verbose", Aliases: []string{"V"}}, &StringFlag{Name: "version", Aliases: []string{"V"}}},
+			dup:   "V",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := &App{
+				Writer: ioutil.Discard,
+				Commands: []*Command{{
+					Name:   "cmd",
+					Flags:  test.flags,
+					Action: func(*Context) error { return nil },
+				}},
+			}
+
+			err := app.Run([]string{"run", "cmd"})
+			expect(t, err, fmt.Errorf("flag name %q is used by more than one flag of cmd", test.dup))
+		})
+	}
+}
+
+func TestCommand_Run_BashCompleteSeesParsedFlags(t *testing.T) {
+	defer func(args []string) { os.Args = args }(os.Args)
+
+	branches := map[string][]string{
+		"cli":  {"main", "v1"},
+		"docs": {"gh-pages"},
+	}
+	newApp := func(w io.Writer) *App {
+		return &App{
+			Name:                 "git",
+			EnableBashCompletion: true,
+			Writer:               w,
+			Commands: []*Command{{
+				Name: "checkout",
+				Flags: []Flag{
+					&StringFlag{Name: "repo"},
+					&StringFlag{
+						Name: "branch",
+						CompletionFunc: func(c *Context) []string {
+							return branches[c.String("repo")]
+						},
+					},
+				},
+				BashComplete: func(c *Context) {
+					_, _ = fmt.Fprintf(c.App.Writer, "repo=%s\n", c.String("repo"))
+				},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "flag value",
+			args: []string{"git", "checkout", "--repo", "cli", "--branch", "--generate-bash-completion"},
+			want: "main\nv1\n",
+		},
+		{
+			name: "partial flag",
+			args: []string{"git", "checkout", "--repo", "docs", "--br", "--generate-bash-completion"},
+			want: "repo=docs\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			os.Args = test.args
+			buf := new(bytes.Buffer)
+
+			err := newApp(buf).Run(test.args)
+			expect(t, err, nil)
+			expect(t, buf.String(), test.want)
+		})
+	}
+}
+
+func TestCommand_Run_NegativeNumbers(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantOffset int
+		wantOne    bool
+		wantArgs   []string
+	}{
+		{name: "flag value", args: []string{"--offset", "-5"}, wantOffset: -5, wantArgs: []string{}},
+		{name: "flag value with equals", args: []string{"--offset=-5"}, wantOffset: -5, wantArgs: []string{}},
+		{name: "positional", args: []string{"-5"}, wantArgs: []string{"-5"}},
+		{name: "decimal positional", args: []string{"-v", "-0.5", "-3"}, wantArgs: []string{"-0.5", "-3"}},
+		{name: "positional after flags", args: []string{"--offset", "2", "-v", "-5", "x"}, wantOffset: 2, wantArgs: []string{"-5", "x"}},
+		{name: "flag named like a number", args: []string{"-1", "-5"}, wantOne: true, wantArgs: []string{"-5"}},
+		{name: "after terminator", args: []string{"--", "-5"}, wantArgs: []string{"-5"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var offset int
+			var one bool
+			var args []string
+			app := &App{
+				Writer: ioutil.Discard,
+				Commands: []*Command{{
+					Name: "move",
+					Flags: []Flag{
+						&IntFlag{Name: "offset"},
+						&BoolFlag{Name: "v"},
+						&BoolFlag{Name: "1"},
+					},
+					Action: func(c *Context) error {
+						offset = c.Int("offset")
+						one = c.Bool("1")
+						args = c.Args().Slice()
+						return nil
+					},
+				}},
+			}
+
+			err := app.Run(append([]string{"calc", "move"}, test.args...))
+			expect(t, err, nil)
+			expect(t, offset, test.wantOffset)
+			expect(t, one, test.wantOne)
+			expect(t, args, test.wantArgs)
+		})
+	}
+}
+
+func TestCommand_ArgsCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max int
+		args     []string
+		wantErr  string
+	}{
+		{name: "exact", min: 2, max: 2, args: []string{"src", "dst"}},
+		{name: "exact too few", min: 2, max: 2, args: []string{"src"}, wantErr: "command copy requires exactly 2 arguments, got 1"},
+		{name: "exact too many", min: 2, max: 2, args: []string{"a", "b", "c"}, wantErr: "command copy requires exactly 2 arguments, got 3"},
+		{name: "too few", min: 1, args: []string{}, wantErr: "command copy requires at least 1 argument, got 0"},
+		{name: "no limit", min: 1, args: []string{"a", "b", "c"}},
+		{name: "too many", max: 1, args: []string{"a", "b"}, wantErr: "command copy accepts at most 1 argument, got 2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			ran := false
+			app := &App{
+				Writer: &out,
+				Commands: []*Command{{
+					Name:      "copy",
+					ArgsUsage: "<src> <dst>",
+					MinArgs:   test.min,
+					MaxArgs:   test.max,
+					Action: func(*Context) error {
+						ran = true
+						return nil
+					},
+				}},
+			}
+
+			err := app.Run(append([]string{"cp", "copy"}, test.args...))
+			if test.wantErr == "" {
+				expect(t, err, nil)
+				expect(t, ran, true)
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			expect(t, err.Error(), test.wantErr)
+			expect(t, ran, false)
+			if !strings.Contains(out.String(), "<src> <dst>") {
+				t.Errorf("expected help to be shown, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestCommand_HideErrors(t *testing.T) {
+	for _, test := range []struct {
+		args        []string
+		wantErr     string
+		wantCommand string
+	}{
+		{args: []string{"app", "deploy", "--target", "a", "-t", "b"}, wantErr: "invalid flags for deploy: Cannot use two forms of the same flag: t target", wantCommand: "deploy"},
+		{args: []string{"app", "deploy", "--count", "x"}, wantErr: `invalid value "x" for flag -count`},
+		{args: []string{"app", "deploy"}, wantErr: `Required flag "target" not set`},
+		{args: []string{"app", "db", "--force", "-f"}, wantErr: "invalid flags for app db: Cannot use two forms of the same flag: f force", wantCommand: "app db"},
+	} {
+		var out, errOut bytes.Buffer
+		app := &App{
+			Name:       "app",
+			Writer:     &out,
+			ErrWriter:  &errOut,
+			HideErrors: true,
+			Commands: []*Command{
+				{
+					Name: "deploy",
+					Flags: []Flag{
+						&StringFlag{Name: "target", Aliases: []string{"t"}, Required: true},
+						&IntFlag{Name: "count"},
+					},
+					Action: func(*Context) error { return nil },
+				},
+				{
+					Name:        "db",
+					Flags:       []Flag{&BoolFlag{Name: "force", Aliases: []string{"f"}}},
+					Subcommands: []*Command{{Name: "migrate"}},
+				},
+			},
+		}
+
+		err := app.Run(test.args)
+		if err == nil {
+			t.Fatalf("expected an error for %v", test.args)
+		}
+		if !strings.HasPrefix(err.Error(), test.wantErr) {
+			t.Errorf("expected error %q, got %q", test.wantErr, err.Error())
+		}
+		if test.wantCommand != "" {
+			var flagsErr *InvalidFlagsError
+			if !errors.As(err, &flagsErr) {
+				t.Fatalf("expected an InvalidFlagsError, got %T", err)
+			}
+			expect(t, flagsErr.Command, test.wantCommand)
+			expect(t, errors.Unwrap(err), flagsErr.Err)
+		}
+		expect(t, out.String(), "")
+		expect(t, errOut.String(), "")
+	}
+}
+
+func TestCommand_UseRawParsing(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantName string
+		wantArgs []string
+		wantErr  string
+	}{
+		{name: "positionals after flags", args: []string{"--name", "x", "pos", "--name", "y", "-ab"}, wantName: "x", wantArgs: []string{"pos", "--name", "y", "-ab"}},
+		{name: "terminator", args: []string{"--", "--name"}, wantArgs: []string{"--name"}},
+		{name: "combined short options", args: []string{"-ab"}, wantErr: "flag provided but not defined: -ab"},
+		{name: "negative number", args: []string{"-5"}, wantErr: "flag provided but not defined: -5"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var name string
+			var args []string
+			newCommand := func() *Command {
+				return &Command{
+					Name:          "exec",
+					UseRawParsing: true,
+					Flags: []Flag{
+						&StringFlag{Name: "name"},
+						&BoolFlag{Name: "a"},
+						&BoolFlag{Name: "b"},
+					},
+					Action: func(c *Context) error {
+						name = c.String("name")
+						args = c.Args().Slice()
+						return nil
+					},
+				}
+			}
+
+			for _, cmd := range []*Command{
+				newCommand(),
+				{Name: "exec", UseRawParsing: true, Flags: newCommand().Flags, Subcommands: []*Command{{Name: "sub"}}, Action: newCommand().Action},
+			} {
+				name, args = "", nil
+				err := (&App{
+					Writer:                 ioutil.Discard,
+					UseShortOptionHandling: true,
+					Commands:               []*Command{cmd},
+				}).Run(append([]string{"app", "exec"}, test.args...))
+
+				if test.wantErr != "" {
+					if err == nil || err.Error() != test.wantErr {
+						t.Errorf("expected error %q, got %v", test.wantErr, err)
+					}
+					continue
+				}
+				expect(t, err, nil)
+				expect(t, name, test.wantName)
+				expect(t, args, test.wantArgs)
+			}
+		})
+	}
+}
+
+func TestCommand_NoDefaultHelpOnUnknownSub(t *testing.T) {
+	for _, noDefault := range []bool{false, true} {
+		var out bytes.Buffer
+		ran := false
+		app := &App{
+			Name:   "app",
+			Writer: &out,
+			Commands: []*Command{
+				{
+					Name:                      "remote",
+					NoDefaultHelpOnUnknownSub: noDefault,
+					Subcommands:               []*Command{{Name: "add"}},
+					Action: func(*Context) error {
+						ran = true
+						return nil
+					},
+				},
+			},
+		}
+
+		err := app.Run([]string{"app", "remote", "ad"})
+		if !noDefault {
+			expect(t, err, nil)
+			expect(t, ran, true)
+			continue
+		}
+
+		if err == nil {
+			t.Fatal("expected an error for an unknown subcommand")
+		}
+		expect(t, err.Error(), `unknown subcommand "ad" for app remote`)
+		if _, ok := err.(ExitCoder); ok {
+			t.Error("expected an error that does not exit the program")
+		}
+		expect(t, ran, false)
+		if !strings.Contains(out.String(), "app remote command [command options]") {
+			t.Errorf("expected the help of the subcommands, got %q", out.String())
+		}
+
+		err = app.Run([]string{"app", "remote"})
+		expect(t, err, nil)
+		expect(t, ran, true)
+	}
+}
+
+func TestCommand_NoDefaultHelpOnUnknownSub_OnCommandNotFound(t *testing.T) {
+	errUnknown := errors.New("no such remote command")
+	var notFound string
+	app := &App{
+		Name:       "app",
+		Writer:     ioutil.Discard,
+		HideErrors: true,
+		OnCommandNotFound: func(c *Context, command string, err error) error {
+			notFound = command
+			expect(t, err.Error(), `unknown subcommand "ad" for app remote`)
+			return errUnknown
+		},
+		Commands: []*Command{
+			{
+				Name:                      "remote",
+				NoDefaultHelpOnUnknownSub: true,
+				Subcommands:               []*Command{{Name: "add"}},
+			},
+		},
+	}
+
+	err := app.Run([]string{"app", "remote", "ad"})
+	expect(t, err, errUnknown)
+	expect(t, notFound, "ad")
+}
+
+func TestCommand_RequireConfirmation(t *testing.T) {
+	tests := []struct {
+		args    []string
+		input   string
+		ran     bool
+		aborted bool
+	}{
+		{args: []string{"app", "delete"}, input: "y\n", ran: true},
+		{args: []string{"app", "delete"}, input: "YES\n", ran: true},
+		{args: []string{"app", "delete"}, input: "n\n", aborted: true},
+		{args: []string{"app", "delete"}, input: "", aborted: true},
+		{args: []string{"app", "delete", "--yes"}, ran: true},
+		{args: []string{"app", "delete", "--force"}, ran: true},
+		{args: []string{"app", "--force", "delete"}, input: "n\n", aborted: true},
+		{args: []string{"app", "--yes", "delete"}, input: "n\n", aborted: true},
+	}
+
+	for _, test := range tests {
+		out := &bytes.Buffer{}
+		ran := false
+		app := &App{
+			Name:           "app",
+			Reader:         strings.NewReader(test.input),
+			Writer:         out,
+			ErrWriter:      ioutil.Discard,
+			ExitErrHandler: func(*Context, error) {},
+			Flags:          []Flag{&BoolFlag{Name: "force"}, &BoolFlag{Name: "yes"}},
+			Commands: []*Command{
+				{
+					Name:                "delete",
+					RequireConfirmation: true,
+					ConfirmSkipFlag:     "force",
+					Flags:               []Flag{&BoolFlag{Name: "force"}},
+					Action: func(*Context) error {
+						ran = true
+						return nil
+					},
+				},
+			},
+		}
+
+		err := app.Run(test.args)
+		expect(t, ran, test.ran)
+		if test.aborted {
+			if err == nil {
+				t.Fatalf("expected an error for %v with input %q", test.args, test.input)
+			}
+			expect(t, err.Error(), "aborted")
+			expect(t, err.(ExitCoder).ExitCode(), 1)
+		} else {
+			expect(t, err, nil)
+		}
+
+		prompted := strings.Contains(out.String(), `Are you sure you want to run "delete"? [y/N] `)
+		expect(t, prompted, test.input != "" || test.aborted)
+	}
+}
+
+func TestCommand_PassThroughUnknownFlags(t *testing.T) {
+	var (
+		passthrough []string
+		positional  []string
+		namespace   string
+		dryRun      bool
+	)
+	app := &App{
+		Writer: ioutil.Discard,
+		Commands: []*Command{
+			{
+				Name:                    "kube",
+				PassThroughUnknownFlags: true,
+				Flags: []Flag{
+					&StringFlag{Name: "namespace", Aliases: []string{"n"}},
+					&BoolFlag{Name: "dry-run"},
+				},
+				Action: func(c *Context) error {
+					passthrough = c.PassthroughArgs()
+					positional = c.Args().Slice()
+					namespace = c.String("namespace")
+					dryRun = c.Bool("dry-run")
+					return nil
+				},
+			},
+		},
+	}
+
+	err := app.Run([]string{"app", "kube", "--context", "prod", "-n", "web", "--output=json", "--dry-run", "--watch", "-v", "3", "get", "pods"})
+	expect(t, err, nil)
+	expect(t, namespace, "web")
+	expect(t, dryRun, true)
+	expect(t, passthrough, []string{"--context", "prod", "--output=json", "--watch", "-v", "3"})
+	expect(t, positional, []string{"get", "pods"})
+
+	err = app.Run([]string{"app", "kube", "-n", "web", "--", "--context", "prod"})
+	expect(t, err, nil)
+	expect(t, len(passthrough), 0)
+	expect(t, positional, []string{"--context", "prod"})
+}
+
+func TestCommand_PersistentFlags(t *testing.T) {
+	var verbose, quiet bool
+	var level int
+	app := &App{
+		Writer: ioutil.Discard,
+		PersistentFlags: []Flag{
+			&BoolFlag{Name: "verbose", Aliases: []string{"v"}},
+			&IntFlag{Name: "level", Value: 1},
+		},
+		Commands: []*Command{
+			{
+				Name: "remote",
+				Subcommands: []*Command{
+					{
+						Name: "add",
+						Flags: []Flag{
+							// overrides the persistent flag
+							&BoolFlag{Name: "level"},
+							&BoolFlag{Name: "quiet", Aliases: []string{"q"}},
+						},
+						Action: func(c *Context) error {
+							verbose = c.Bool("verbose")
+							quiet = c.Bool("quiet")
+							return nil
+						},
+					},
+					{
+						Name: "remove",
+						Action: func(c *Context) error {
+							verbose = c.Bool("v")
+							level = c.Int("level")
+							return nil
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, args := range [][]string{
+		{"app", "-v", "remote", "add"},
+		{"app", "remote", "--verbose", "add"},
+		{"app", "remote", "add", "-v"},
+	} {
+		verbose = false
+		err := app.Run(args)
+		expect(t, err, nil)
+		expect(t, verbose, true)
+	}
+
+	err := app.Run([]string{"app", "--level", "3", "remote", "remove"})
+	expect(t, err, nil)
+	expect(t, level, 3)
+	expect(t, verbose, false)
+
+	err = app.Run([]string{"app", "remote", "add", "--level", "-q"})
+	expect(t, err, nil)
+	expect(t, quiet, true)
+}
+
+func TestCommand_Middleware(t *testing.T) {
+	var calls []string
+	record := func(name string) MiddlewareFunc {
+		return func(next ActionFunc) ActionFunc {
+			return func(c *Context) error {
+				calls = append(calls, name+" before")
+				err := next(c)
+				calls = append(calls, name+" after")
+				return err
+			}
+		}
+	}
+
+	app := &App{
+		Writer:     ioutil.Discard,
+		Middleware: []MiddlewareFunc{record("outer"), record("inner")},
+		Commands: []*Command{
+			{
+				Name: "foo",
+				Action: func(c *Context) error {
+					calls = append(calls, "foo")
+					return nil
+				},
+				Subcommands: []*Command{
+					{
+						Name: "bar",
+						Action: func(c *Context) error {
+							calls = append(calls, "bar")
+							return nil
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err := app.Run([]string{"app", "foo", "bar"})
+	expect(t, err, nil)
+	expect(t, calls, []string{"outer before", "inner before", "bar", "inner after", "outer after"})
+
+	// the action of a command with subcommands is wrapped as well
+	calls = nil
+	err = app.Run([]string{"app", "foo"})
+	expect(t, err, nil)
+	expect(t, calls, []string{"outer before", "inner before", "foo", "inner after", "outer after"})
+
+	// a middleware not calling next skips the action
+	calls = nil
+	app.Middleware = append([]MiddlewareFunc{func(next ActionFunc) ActionFunc {
+		return func(c *Context) error {
+			return errors.New("denied")
+		}
+	}}, app.Middleware...)
+	err = app.Run([]string{"app", "foo", "bar"})
+	expect(t, err.Error(), "denied")
+	expect(t, len(calls), 0)
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// completionGenerators are the completion script generators of the App keyed
+// by the name of their shell
+var completionGenerators = map[string]func(a *App) (string, error){
+	"bash": (*App).ToBashCompletion,
+	"fish": (*App).ToFishCompletion,
+	"zsh":  (*App).ToZshCompletion,
+}
+
+// completionCommand prints the completion script for the shell given as its
+// argument. It is added to Apps with EnableBashCompletion and is hidden from
+// the help and completion listings, but its help is shown when asked for.
+var completionCommand = &Command{
+	Name:      "completion",
+	Usage:     "Output the shell completion script",
+	ArgsUsage: "bash|fish|zsh",
+	Hidden:    true,
+	Action: func(c *Context) error {
+		shell := c.Args().First()
+		generate, ok := completionGenerators[shell]
+		if !ok {
+			return Exit(fmt.Sprintf("unknown shell %q, supported shells are: %s", shell, strings.Join(completionShells(), ", ")), 1)
+		}
+
+		script, err := generate(c.App)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(c.App.Writer, script)
+		return err
+	},
+}
+
+// completionShells returns the sorted names of the shells that completion
+// scripts can be generated for
+func completionShells() []string {
+	var shells []string
+	for shell := range completionGenerators {
+		shells = append(shells, shell)
+	}
+	sort.Strings(shells)
+	return shells
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestBashCompletion(t *testing.T) {
+	app := &App{Name: "greet"}
+
+	res, err := app.ToBashCompletion()
+	expect(t, err, nil)
+	if !strings.Contains(res, "opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} --generate-bash-completion )") {
+		t.Errorf("expected the script to complete through the completion flag, got %q", res)
+	}
+	if !strings.HasSuffix(res, "complete -o bashdefault -o default -o nospace -F _greet_bash_autocomplete greet\n") {
+		t.Errorf("expected the script to register the completion for greet, got %q", res)
+	}
+}
+
+func TestApp_CompletionCommand(t *testing.T) {
+	for _, shell := range []string{"bash", "fish", "zsh"} {
+		var out bytes.Buffer
+		app := &App{Name: "greet", EnableBashCompletion: true, Writer: &out}
+		expect(t, app.Run([]string{"greet", "completion", shell}), nil)
+
+		expected, err := completionGenerators[shell](app)
+		expect(t, err, nil)
+		expect(t, out.String(), expected)
+	}
+}
+
+func TestApp_CompletionCommandUnknownShell(t *testing.T) {
+	app := &App{
+		Name:                 "greet",
+		EnableBashCompletion: true,
+		Writer:               ioutil.Discard,
+		ErrWriter:            ioutil.Discard,
+		ExitErrHandler:       func(*Context, error) {},
+	}
+
+	err := app.Run([]string{"greet", "completion", "tcsh"})
+	expect(t, err.Error(), `unknown shell "tcsh", supported shells are: bash, fish, zsh`)
+}
+
+func TestApp_CompletionCommandHidden(t *testing.T) {
+	var out bytes.Buffer
+	app := &App{
+		Name:                 "greet",
+		EnableBashCompletion: true,
+		Writer:               &out,
+		Commands:             []*Command{{Name: "hello"}},
+	}
+
+	expect(t, app.Run([]string{"greet", "--help"}), nil)
+	if strings.Contains(out.String(), "completion") {
+		t.Errorf("expected the completion command to be hidden, got %q", out.String())
+	}
+
+	out.Reset()
+	expect(t, app.Run([]string{"greet", "help", "completion"}), nil)
+	if !strings.Contains(out.String(), "greet completion bash|fish|zsh") {
+		t.Errorf("expected the help of the completion command, got %q", out.String())
+	}
+
+	app = &App{Name: "greet"}
+	app.Setup()
+	expect(t, app.Command("completion") == nil, true)
+}
@@ -3,7 +3,13 @@ package cli
 import (
 	"context"
 	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
 	"strings"
+	"time"
 )
 
 // Context is a type that is passed through to
@@ -17,6 +23,17 @@ type Context struct {
 	shellComplete bool
 	flagSet       *flag.FlagSet
 	parentContext *Context
+
+	// occurrences counts how often the flags of flagSet were set while
+	// parsing the arguments
+	occurrences occurrences
+
+	// passthroughArgs are the unknown flags collected for a command with
+	// PassThroughUnknownFlags
+	passthroughArgs []string
+
+	// rawArgs are the arguments of the App or Command as they were given
+	rawArgs []string
 }
 
 // NewContext creates a new context. For use in when invoking an App or Command action.
@@ -44,9 +61,43 @@ func (c *Context) NumFlags() int {
 	return c.flagSet.NFlag()
 }
 
-// Set sets a context flag to a value.
+// FlagSet returns the flag set parsed for this context, which holds the
+// flags local to its App or Command. It is meant for interop with code that
+// inspects a *flag.FlagSet, e.g. through Visit or Lookup; changing it after
+// it was parsed is unsupported.
+func (c *Context) FlagSet() *flag.FlagSet {
+	return c.flagSet
+}
+
+// Set sets a context flag to a value. The flag is looked up in this context
+// and all of its parent contexts, and the value is applied once to each of
+// its names that does not share the value of name, so that lookups by any of
+// them see the value. It errors if no such flag exists.
 func (c *Context) Set(name, value string) error {
-	return c.flagSet.Set(name, value)
+	fs := c.lookupFlagSet(name)
+	if fs == nil {
+		return fmt.Errorf("no such flag -%s", name)
+	}
+
+	if err := fs.Set(name, value); err != nil {
+		return err
+	}
+
+	// names sharing the value of name must not be set again, as that would
+	// apply the value twice, e.g. append it twice to a slice
+	if f := c.lookupFlag(name); f != nil {
+		applied := []flag.Value{fs.Lookup(name).Value}
+		for _, alias := range f.Names() {
+			ff := fs.Lookup(alias)
+			if alias == name || ff == nil || containsValue(applied, ff.Value) {
+				continue
+			}
+			applied = append(applied, ff.Value)
+			_ = fs.Set(alias, value)
+		}
+	}
+
+	return nil
 }
 
 // IsSet determines if the flag was actually set
@@ -70,26 +121,227 @@ func (c *Context) IsSet(name string) bool {
 		return f.IsSet()
 	}
 
+	// flags that are not defined in any flag set can only have been set
+	// from the environment or a file
+	if f := c.lookupFlag(name); f != nil {
+		return f.IsSet()
+	}
+
 	return false
 }
 
-// LocalFlagNames returns a slice of flag names used in this context.
+// NumOccurrences returns the number of times the flag was given on the
+// command line by any of its names, e.g. 2 for "--x a --x b" and 1 for
+// "--x a,b". Values set from the environment or a file are not counted.
+func (c *Context) NumOccurrences(name string) int {
+	for _, ctx := range c.Lineage() {
+		if ctx.flagSet != nil && ctx.flagSet.Lookup(name) != nil {
+			return ctx.occurrences.count(name)
+		}
+	}
+
+	return 0
+}
+
+// LocalFlagNames returns a slice of flag names used in this context, including
+// flags set from the environment or a file.
 func (c *Context) LocalFlagNames() []string {
 	var names []string
 	c.flagSet.Visit(makeFlagNameVisitor(&names))
-	return names
+	return c.appendEnvFlagNames(names)
 }
 
 // FlagNames returns a slice of flag names used by the this context and all of
-// its parent contexts.
+// its parent contexts, including flags set from the environment or a file.
 func (c *Context) FlagNames() []string {
 	var names []string
 	for _, ctx := range c.Lineage() {
 		ctx.flagSet.Visit(makeFlagNameVisitor(&names))
+		names = ctx.appendEnvFlagNames(names)
 	}
 	return names
 }
 
+// AllFlags returns the value of each flag of this context and all of its
+// parent contexts keyed by its primary name, e.g. to log the effective
+// configuration. The values are those of the typed accessors, such as a
+// []string for a StringSliceFlag, and SensitiveFlags marked as sensitive
+// have their value replaced by "***". The flags of a command take precedence over the
+// flags of the same name of its parents.
+func (c *Context) AllFlags() map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, ctx := range c.Lineage() {
+		var flags []Flag
+		if ctx.Command != nil {
+			flags = append(flags, ctx.Command.Flags...)
+		}
+		if ctx.App != nil {
+			flags = append(flags, ctx.App.Flags...)
+		}
+
+		for _, f := range flags {
+			names := f.Names()
+			if len(names) == 0 {
+				continue
+			}
+			name := names[0]
+			if _, ok := values[name]; ok || ctx.flagSet == nil || ctx.flagSet.Lookup(name) == nil {
+				continue
+			}
+
+			if sf, ok := f.(SensitiveFlag); ok && sf.IsSensitive() {
+				values[name] = "***"
+				continue
+			}
+			values[name] = ctx.flagValueOf(f, name)
+		}
+	}
+	return values
+}
+
+// flagValueOf returns the value of the flag f named name using the typed
+// accessor of its type
+func (c *Context) flagValueOf(f Flag, name string) interface{} {
+	switch f.(type) {
+	case *StringSliceFlag:
+		return c.StringSlice(name)
+	case *IntSliceFlag:
+		return c.IntSlice(name)
+	case *Int64SliceFlag:
+		return c.Int64Slice(name)
+	case *Float64SliceFlag:
+		return c.Float64Slice(name)
+	case *StringMapFlag:
+		return c.StringMap(name)
+	case *TimestampFlag:
+		return c.Timestamp(name)
+	case *GenericFlag:
+		return c.Generic(name)
+	}
+
+	if getter, ok := c.flagSet.Lookup(name).Value.(flag.Getter); ok {
+		return getter.Get()
+	}
+	return c.flagSet.Lookup(name).Value.String()
+}
+
+// appendEnvFlagNames appends the names of the flags of this context that were
+// set from the environment or a file, and so were not visited in its flag
+// set, to names unless they are already present.
+func (c *Context) appendEnvFlagNames(names []string) []string {
+	var flags []Flag
+	if c.Command != nil {
+		flags = append(flags, c.Command.Flags...)
+	}
+	if c.App != nil {
+		flags = append(flags, c.App.Flags...)
+	}
+
+	for _, f := range flags {
+		if !f.IsSet() {
+			continue
+		}
+		for _, name := range f.Names() {
+			name = strings.TrimSpace(name)
+			if c.flagSet == nil || c.flagSet.Lookup(name) == nil || containsString(names, name) {
+				continue
+			}
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func containsString(slice []string, s string) bool {
+	for _, v := range slice {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Parent returns the context of the enclosing App or Command, or nil for the
+// context of the top level App
+func (c *Context) Parent() *Context {
+	return c.parentContext
+}
+
+// Confirm writes prompt followed by " [y/N] " to the Writer of the App and
+// reads the answer from its Reader, or from os.Stdin if it has none. It
+// returns true for "y" or "yes" and false for "n", "no" or no answer, case
+// insensitively, and errors for any other answer.
+func (c *Context) Confirm(prompt string) (bool, error) {
+	var r io.Reader = os.Stdin
+	var w io.Writer = os.Stdout
+	if c.App != nil {
+		if c.App.Reader != nil {
+			r = c.App.Reader
+		}
+		if c.App.Writer != nil {
+			w = c.App.Writer
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "%s [y/N] ", prompt)
+	answer, err := readLine(r)
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	case "", "n", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid answer %q, expected y or n", strings.TrimSpace(answer))
+}
+
+// readLine reads from r up to and excluding the next newline or the end of
+// r. It reads a byte at a time so that nothing after the line is consumed.
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				break
+			}
+			line = append(line, b[0])
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return string(line), nil
+}
+
+// InputReader returns a reader for the file named by the value of the
+// StringFlag or PathFlag name, following the convention that "-" means
+// standard input: for "-" it returns the Reader of the App, or os.Stdin if
+// it has none, and otherwise it opens the file. The caller should close
+// the returned reader, which does not close the Reader of the App.
+func (c *Context) InputReader(name string) (io.ReadCloser, error) {
+	path, ok := c.TryString(name)
+	if !ok {
+		return nil, fmt.Errorf("no such flag -%s", name)
+	}
+	if path != "-" {
+		return os.Open(path)
+	}
+
+	var r io.Reader = os.Stdin
+	if c.App != nil && c.App.Reader != nil {
+		r = c.App.Reader
+	}
+	return ioutil.NopCloser(r), nil
+}
+
 // Lineage returns *this* context and all of its ancestor contexts in order from
 // child to parent
 func (c *Context) Lineage() []*Context {
@@ -110,17 +362,91 @@ func (c *Context) Value(name string) interface{} {
 	return nil
 }
 
+// Default returns the default value of the flag corresponding to `name`,
+// which is the Value the flag was declared with, or nil if there is no such
+// flag. Slice, map and timestamp defaults are returned as they are by the
+// typed accessors, e.g. as a []string for a StringSliceFlag, so that they can
+// be compared with the current value. Note that a value from the environment
+// or a file replaces the Value of the flag when it is applied.
+func (c *Context) Default(name string) interface{} {
+	f := c.lookupFlag(name)
+	if f == nil {
+		return nil
+	}
+
+	value := flagValue(f).FieldByName("Value")
+	if !value.IsValid() {
+		return nil
+	}
+
+	switch v := value.Interface().(type) {
+	case *StringSlice:
+		if v == nil {
+			return []string(nil)
+		}
+		return v.Value()
+	case *IntSlice:
+		if v == nil {
+			return []int(nil)
+		}
+		return v.Value()
+	case *Int64Slice:
+		if v == nil {
+			return []int64(nil)
+		}
+		return v.Value()
+	case *Float64Slice:
+		if v == nil {
+			return []float64(nil)
+		}
+		return v.Value()
+	case *StringMap:
+		if v == nil {
+			return map[string]string(nil)
+		}
+		return v.Value()
+	case *Timestamp:
+		if v == nil {
+			return (*time.Time)(nil)
+		}
+		return v.Value()
+	}
+	return value.Interface()
+}
+
 // Args returns the command line arguments associated with the context.
 func (c *Context) Args() Args {
 	ret := args(c.flagSet.Args())
 	return &ret
 }
 
+// RawArgs returns the arguments given to the App or Command, after its name,
+// as they were given, with the flags in place and before short options are
+// split or App.ArgsRewriter is applied, e.g. to hand them over to another
+// program verbatim. Args returns the positional arguments left after the
+// flags are parsed instead.
+func (c *Context) RawArgs() []string {
+	return c.rawArgs
+}
+
+// PassthroughArgs returns the flags, with their values, that the command
+// does not define when it has PassThroughUnknownFlags set, in the order they
+// were given, e.g. to hand them over to another program.
+func (c *Context) PassthroughArgs() []string {
+	return c.passthroughArgs
+}
+
 // NArg returns the number of the command line arguments.
 func (c *Context) NArg() int {
 	return c.Args().Len()
 }
 
+// Arg returns the ith command line argument, Arg(0) being the first one
+// left after the flags, or an empty string if there is no such argument.
+func (c *Context) Arg(i int) string {
+	return c.Args().Get(i)
+}
+
 func (ctx *Context) lookupFlag(name string) Flag {
 	for _, c := range ctx.Lineage() {
 		if c.Command == nil {
@@ -159,10 +485,117 @@ func (ctx *Context) lookupFlagSet(name string) *flag.FlagSet {
 	return nil
 }
 
+// inheritPersistentFlags sets each of the PersistentFlags of the App that is
+// among flags, and was not given at this level, to the value it was given
+// at the closest level above, so that it can be given at any level
+func (context *Context) inheritPersistentFlags(flags []Flag) {
+	if context.App == nil || context.parentContext == nil {
+		return
+	}
+
+	for _, f := range context.App.PersistentFlags {
+		if !hasFlag(flags, f) || context.lookupSetFlag(f.Names()) != nil {
+			continue
+		}
+		for _, ctx := range context.parentContext.Lineage() {
+			if ff := ctx.lookupSetFlag(f.Names()); ff != nil {
+				for _, name := range f.Names() {
+					copyFlag(name, ff, context.flagSet)
+					if n := ctx.occurrences[name]; n != nil {
+						if context.occurrences == nil {
+							context.occurrences = occurrences{}
+						}
+						context.occurrences[name] = n
+					}
+				}
+				break
+			}
+		}
+	}
+}
+
+// lookupSetFlag returns the first of names that was set in the flag set of
+// the context itself, or nil if none was
+func (context *Context) lookupSetFlag(names []string) *flag.Flag {
+	if context.flagSet == nil {
+		return nil
+	}
+	var found *flag.Flag
+	context.flagSet.Visit(func(f *flag.Flag) {
+		for _, name := range names {
+			if found == nil && f.Name == name {
+				found = f
+			}
+		}
+	})
+	return found
+}
+
+// warnDeprecatedFlags prints a warning to the ErrWriter of the App for each
+// of flags that is deprecated and was set, and for each of flags whose value
+// was read from one of the DeprecatedEnvVars of the App
+func (context *Context) warnDeprecatedFlags(flags []Flag) {
+	w := ErrWriter
+	if context.App != nil && context.App.ErrWriter != nil {
+		w = context.App.ErrWriter
+	}
+
+	for _, f := range flags {
+		context.warnDeprecatedEnvVar(w, f)
+
+		deprecated := flagDeprecation(f)
+		if deprecated == "" {
+			continue
+		}
+
+		for _, key := range f.Names() {
+			key = strings.TrimSpace(key)
+			if context.IsSet(key) {
+				_, _ = fmt.Fprintf(w, "flag %s%s is deprecated: %s\n", prefixFor(key), key, deprecated)
+				break
+			}
+		}
+	}
+}
+
+// warnDeprecatedEnvVar prints a warning to w if the value of f was read from
+// one of the DeprecatedEnvVars of the App and not given on the command line
+func (context *Context) warnDeprecatedEnvVar(w io.Writer, f Flag) {
+	if context.App == nil || len(context.App.DeprecatedEnvVars) == 0 || !f.IsSet() {
+		return
+	}
+
+	names := f.Names()
+	if len(names) == 0 || context.NumOccurrences(names[0]) > 0 {
+		return
+	}
+
+	_, envVar, ok, _ := flagFromEnvOrFileWithSource(flagStringSliceField(f, "EnvVars"), "")
+	if !ok {
+		return
+	}
+
+	replacement, deprecated := context.App.DeprecatedEnvVars[envVar]
+	if !deprecated {
+		return
+	}
+	if replacement != "" {
+		_, _ = fmt.Fprintf(w, "environment variable %s is deprecated, use %s instead\n", envVar, replacement)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "environment variable %s is deprecated\n", envVar)
+}
+
 func (context *Context) checkRequiredFlags(flags []Flag) requiredFlagsErr {
 	var missingFlags []string
 	for _, f := range flags {
+		required := false
 		if rf, ok := f.(RequiredFlag); ok && rf.IsRequired() {
+			required = true
+		} else if rf, ok := f.(RequiredWhenFlag); ok {
+			required = rf.IsRequiredWhen(context)
+		}
+		if required {
 			var flagPresent bool
 			var flagName string
 
@@ -176,6 +609,12 @@ func (context *Context) checkRequiredFlags(flags []Flag) requiredFlagsErr {
 				}
 			}
 
+			// fall back to a single character name if the flag has no
+			// longer one
+			if flagName == "" && len(f.Names()) > 0 {
+				flagName = strings.TrimSpace(f.Names()[0])
+			}
+
 			if !flagPresent && flagName != "" {
 				missingFlags = append(missingFlags, flagName)
 			}
@@ -183,7 +622,7 @@ func (context *Context) checkRequiredFlags(flags []Flag) requiredFlagsErr {
 	}
 
 	if len(missingFlags) != 0 {
-		return &errRequiredFlags{missingFlags: missingFlags}
+		return &RequiredFlagError{Flags: missingFlags}
 	}
 
 	return nil
@@ -206,3 +645,15 @@ func makeFlagNameVisitor(names *[]string) func(*flag.Flag) {
 		}
 	}
 }
+
+func containsValue(values []flag.Value, value flag.Value) bool {
+	if !reflect.TypeOf(value).Comparable() {
+		return false
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,13 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"flag"
+	"io/ioutil"
 	"os"
+	"reflect"
 	"sort"
 	"strings"
 	"testing"
@@ -148,6 +152,119 @@ func TestContext_Value(t *testing.T) {
 	expect(t, c.Value("unknown-flag"), nil)
 }
 
+func TestContext_Default(t *testing.T) {
+	var defaults, values map[string]interface{}
+	app := &App{
+		Flags: []Flag{
+			&IntFlag{Name: "workers", Aliases: []string{"w"}, Value: 4},
+			&StringFlag{Name: "name", Value: "default"},
+			&StringSliceFlag{Name: "tags", Value: NewStringSlice("a", "b")},
+		},
+		Commands: []*Command{
+			{
+				Name:  "cmd",
+				Flags: []Flag{&BoolFlag{Name: "force"}},
+				Action: func(c *Context) error {
+					defaults = map[string]interface{}{
+						"w":     c.Default("w"),
+						"name":  c.Default("name"),
+						"tags":  c.Default("tags"),
+						"force": c.Default("force"),
+						"none":  c.Default("unknown-flag"),
+					}
+					values = map[string]interface{}{
+						"w":     c.Int("w"),
+						"name":  c.String("name"),
+						"tags":  c.StringSlice("tags"),
+						"force": c.Bool("force"),
+					}
+					return nil
+				},
+			},
+		},
+	}
+
+	err := app.Run([]string{"run", "-w", "8", "--tags", "a", "--tags", "b", "cmd", "--force"})
+	expect(t, err, nil)
+	expect(t, defaults["w"], 4)
+	expect(t, defaults["name"], "default")
+	expect(t, defaults["tags"], []string{"a", "b"})
+	expect(t, defaults["force"], false)
+	expect(t, defaults["none"], nil)
+
+	expect(t, values["w"] == defaults["w"], false)
+	expect(t, values["name"] == defaults["name"], true)
+	expect(t, reflect.DeepEqual(values["tags"], defaults["tags"]), true)
+	expect(t, values["force"] == defaults["force"], false)
+}
+
+func TestContext_Confirm(t *testing.T) {
+	tests := []struct {
+		input     string
+		confirmed bool
+		err       string
+	}{
+		{input: "y\n", confirmed: true},
+		{input: "Yes\n", confirmed: true},
+		{input: "y", confirmed: true},
+		{input: "n\n"},
+		{input: "no\n"},
+		{input: "\n"},
+		{input: ""},
+		{input: "maybe\n", err: `invalid answer "maybe", expected y or n`},
+	}
+
+	for _, test := range tests {
+		out := &bytes.Buffer{}
+		c := NewContext(&App{Reader: strings.NewReader(test.input), Writer: out}, nil, nil)
+
+		confirmed, err := c.Confirm("Delete everything?")
+		expect(t, out.String(), "Delete everything? [y/N] ")
+		expect(t, confirmed, test.confirmed)
+		if test.err != "" {
+			expect(t, err.Error(), test.err)
+		} else {
+			expect(t, err, nil)
+		}
+	}
+
+	// only the line of the answer is read
+	r := strings.NewReader("y\nn\n")
+	c := NewContext(&App{Reader: r, Writer: ioutil.Discard}, nil, nil)
+	confirmed, _ := c.Confirm("First?")
+	expect(t, confirmed, true)
+	confirmed, _ = c.Confirm("Second?")
+	expect(t, confirmed, false)
+}
+
+func TestContext_InputReader(t *testing.T) {
+	set := flag.NewFlagSet("test", 0)
+	set.String("file", "-", "doc")
+	c := NewContext(&App{Reader: strings.NewReader("from stdin")}, set, nil)
+
+	r, err := c.InputReader("file")
+	expect(t, err, nil)
+	b, _ := ioutil.ReadAll(r)
+	expect(t, string(b), "from stdin")
+	expect(t, r.Close(), nil)
+
+	f, err := ioutil.TempFile("", "urfave_cli_input")
+	expect(t, err, nil)
+	defer os.Remove(f.Name())
+	_, _ = f.WriteString("from file")
+	_ = f.Close()
+
+	_ = set.Set("file", f.Name())
+	r, err = c.InputReader("file")
+	expect(t, err, nil)
+	b, _ = ioutil.ReadAll(r)
+	expect(t, string(b), "from file")
+	expect(t, r.Close(), nil)
+
+	_, err = c.InputReader("nope")
+	expect(t, err.Error(), "no such flag -nope")
+}
+
 func TestContext_Args(t *testing.T) {
 	set := flag.NewFlagSet("test", 0)
 	set.Bool("myflag", false, "doc")
@@ -157,6 +274,24 @@ func TestContext_Args(t *testing.T) {
 	expect(t, c.Bool("myflag"), true)
 }
 
+func TestContext_ArgsAccessors(t *testing.T) {
+	set := flag.NewFlagSet("test", 0)
+	c := NewContext(nil, set, nil)
+	_ = set.Parse([]string{"bat", "baz", "qux"})
+	expect(t, c.Args().Present(), true)
+	expect(t, c.Args().First(), "bat")
+	expect(t, c.Args().Get(2), "qux")
+	expect(t, c.Args().Get(3), "")
+	expect(t, c.Args().Get(-1), "")
+	expect(t, c.Args().Tail(), []string{"baz", "qux"})
+	expect(t, c.Args().Slice(), []string{"bat", "baz", "qux"})
+
+	empty := NewContext(nil, flag.NewFlagSet("empty", 0), nil)
+	expect(t, empty.Args().Present(), false)
+	expect(t, empty.Args().First(), "")
+	expect(t, empty.Args().Tail(), []string{})
+}
+
 func TestContext_NArg(t *testing.T) {
 	set := flag.NewFlagSet("test", 0)
 	set.Bool("myflag", false, "doc")
@@ -165,6 +300,17 @@ func TestContext_NArg(t *testing.T) {
 	expect(t, c.NArg(), 2)
 }
 
+func TestContext_Arg(t *testing.T) {
+	set := flag.NewFlagSet("test", 0)
+	set.Bool("myflag", false, "doc")
+	c := NewContext(nil, set, nil)
+	_ = set.Parse([]string{"--myflag", "bat", "baz"})
+	expect(t, c.Arg(0), "bat")
+	expect(t, c.Arg(1), "baz")
+	expect(t, c.Arg(2), "")
+	expect(t, c.Arg(-1), "")
+}
+
 func TestContext_IsSet(t *testing.T) {
 	set := flag.NewFlagSet("test", 0)
 	set.Bool("one-flag", false, "doc")
@@ -245,6 +391,72 @@ func TestContext_NumFlags(t *testing.T) {
 	expect(t, c.NumFlags(), 2)
 }
 
+func TestContext_FlagSet(t *testing.T) {
+	var local, global *flag.FlagSet
+	_ = (&App{
+		Flags: []Flag{&StringFlag{Name: "top"}},
+		Commands: []*Command{{
+			Name:  "sub",
+			Flags: []Flag{&StringFlag{Name: "name", Aliases: []string{"n"}}},
+			Action: func(c *Context) error {
+				local = c.FlagSet()
+				global = c.Lineage()[1].FlagSet()
+				return nil
+			},
+		}},
+	}).Run([]string{"run", "--top", "a", "sub", "-n", "b"})
+
+	expect(t, local.Lookup("name").Value.String(), "b")
+	expect(t, local.Lookup("top") == nil, true)
+	expect(t, global.Lookup("top").Value.String(), "a")
+}
+
+func TestContext_AllFlags(t *testing.T) {
+	var all map[string]interface{}
+	err := (&App{
+		Flags: []Flag{
+			&StringFlag{Name: "region", Value: "eu"},
+			&StringFlag{Name: "token", Sensitive: true, Value: "secret"},
+			&BoolFlag{Name: "verbose", Aliases: []string{"v"}},
+			&sensitiveFlag{&StringFlag{Name: "password", Value: "secret"}},
+		},
+		Commands: []*Command{{
+			Name: "deploy",
+			Flags: []Flag{
+				&StringSliceFlag{Name: "tag", Aliases: []string{"t"}},
+				&IntFlag{Name: "replicas", Value: 1},
+				&DurationFlag{Name: "timeout", Value: time.Minute},
+				&StringFlag{Name: "region"},
+			},
+			Action: func(c *Context) error {
+				all = c.AllFlags()
+				return nil
+			},
+		}},
+	}).Run([]string{"run", "-v", "--token", "hunter2", "deploy", "-t", "a", "-t", "b", "--replicas", "3", "--region", "us"})
+
+	expect(t, err, nil)
+	expect(t, all, map[string]interface{}{
+		"region":   "us",
+		"token":    "***",
+		"verbose":  true,
+		"tag":      []string{"a", "b"},
+		"replicas": 3,
+		"timeout":  time.Minute,
+		"password": "***",
+		"help":     false,
+	})
+}
+
+// sensitiveFlag is a user-defined flag whose value is always masked
+type sensitiveFlag struct {
+	Flag
+}
+
+func (f *sensitiveFlag) IsSensitive() bool {
+	return true
+}
+
 func TestContext_Set(t *testing.T) {
 	set := flag.NewFlagSet("test", 0)
 	set.Int("int", 5, "an int")
@@ -256,6 +468,212 @@ func TestContext_Set(t *testing.T) {
 	expect(t, c.IsSet("int"), true)
 }
 
+func TestContext_SetParentAndAliases(t *testing.T) {
+	var region string
+	var regionSet bool
+	var setErr error
+	app := &App{
+		Flags: []Flag{
+			&StringFlag{Name: "region", Aliases: []string{"r"}, Value: "eu-west-1"},
+		},
+		Commands: []*Command{
+			{
+				Name: "deploy",
+				Before: func(c *Context) error {
+					setErr = c.Set("missing", "value")
+					return c.Set("region", "us-east-1")
+				},
+				Action: func(c *Context) error {
+					region = c.String("r")
+					regionSet = c.IsSet("region")
+					return nil
+				},
+			},
+		},
+	}
+
+	err := app.Run([]string{"run", "deploy"})
+
+	expect(t, err, nil)
+	expect(t, region, "us-east-1")
+	expect(t, regionSet, true)
+	expect(t, setErr, errors.New("no such flag -missing"))
+}
+
+func TestContext_SetAliasedSlice(t *testing.T) {
+	var tags []string
+	var verbosity int
+	app := &App{
+		Flags: []Flag{
+			&StringSliceFlag{Name: "tag", Aliases: []string{"t"}},
+			&CountFlag{Name: "v", Aliases: []string{"verbose"}},
+		},
+		Action: func(c *Context) error {
+			if err := c.Set("tag", "x"); err != nil {
+				return err
+			}
+			if err := c.Set("v", "true"); err != nil {
+				return err
+			}
+			tags = c.StringSlice("t")
+			verbosity = c.Int("verbose")
+			return nil
+		},
+	}
+
+	err := app.Run([]string{"run", "--tag", "a"})
+
+	expect(t, err, nil)
+	expect(t, tags, []string{"a", "x"})
+	expect(t, verbosity, 1)
+}
+
+func TestContext_Or(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_RETRIES", "0")
+
+	err := (&App{
+		Flags: []Flag{
+			&DurationFlag{Name: "timeout", Aliases: []string{"t"}, Value: time.Second},
+			&DurationFlag{Name: "wait"},
+			&StringFlag{Name: "name"},
+			&IntFlag{Name: "retries", EnvVars: []string{"APP_RETRIES"}},
+			&Int64Flag{Name: "size"},
+			&UintFlag{Name: "workers"},
+			&Uint64Flag{Name: "limit"},
+			&Float64Flag{Name: "ratio"},
+			&BoolFlag{Name: "force", Value: true},
+		},
+		Action: func(c *Context) error {
+			expect(t, c.DurationOr("timeout", time.Minute), time.Duration(0))
+			expect(t, c.DurationOr("wait", time.Minute), time.Minute)
+			expect(t, c.StringOr("name", "anonymous"), "anonymous")
+			expect(t, c.IntOr("retries", 3), 0)
+			expect(t, c.Int64Or("size", 64), int64(64))
+			expect(t, c.UintOr("workers", 4), uint(4))
+			expect(t, c.Uint64Or("limit", 10), uint64(10))
+			expect(t, c.Float64Or("ratio", 0.5), 0.5)
+			expect(t, c.BoolOr("force", false), false)
+			expect(t, c.StringOr("missing", "fallback"), "fallback")
+			return nil
+		},
+	}).Run([]string{"run", "-t", "0s"})
+	expect(t, err, nil)
+}
+
+func TestContext_Try(t *testing.T) {
+	err := (&App{
+		Flags: []Flag{
+			&BoolFlag{Name: "force"},
+			&StringFlag{Name: "name", Aliases: []string{"n"}},
+			&IntFlag{Name: "retries"},
+			&DurationFlag{Name: "timeout", Value: time.Second},
+			&SizeFlag{Name: "limit"},
+		},
+		Action: func(c *Context) error {
+			force, ok := c.TryBool("force")
+			expect(t, force, false)
+			expect(t, ok, true)
+			name, ok := c.TryString("n")
+			expect(t, name, "jane")
+			expect(t, ok, true)
+			retries, ok := c.TryInt("retries")
+			expect(t, retries, 0)
+			expect(t, ok, true)
+			timeout, ok := c.TryDuration("timeout")
+			expect(t, timeout, time.Second)
+			expect(t, ok, true)
+			limit, ok := c.TrySize("limit")
+			expect(t, limit, int64(0))
+			expect(t, ok, true)
+
+			_, ok = c.TryBool("forse")
+			expect(t, ok, false)
+			_, ok = c.TryString("nmae")
+			expect(t, ok, false)
+			_, ok = c.TryUint64("retries-max")
+			expect(t, ok, false)
+			return nil
+		},
+	}).Run([]string{"run", "-n", "jane"})
+	expect(t, err, nil)
+}
+
+func TestContext_RawArgs(t *testing.T) {
+	var appRaw, cmdRaw, cmdArgs []string
+	app := &App{
+		UseShortOptionHandling: true,
+		Flags: []Flag{
+			&BoolFlag{Name: "verbose", Aliases: []string{"v"}},
+		},
+		Before: func(c *Context) error {
+			appRaw = c.RawArgs()
+			return nil
+		},
+		Commands: []*Command{
+			{
+				Name: "exec",
+				Flags: []Flag{
+					&BoolFlag{Name: "all", Aliases: []string{"a"}},
+					&BoolFlag{Name: "force", Aliases: []string{"f"}},
+					&StringFlag{Name: "output", Aliases: []string{"o"}},
+				},
+				Action: func(c *Context) error {
+					cmdRaw = c.RawArgs()
+					cmdArgs = c.Args().Slice()
+					return nil
+				},
+			},
+		},
+	}
+
+	err := app.Run([]string{"app", "-v", "exec", "-af", "--output=json", "target", "-x"})
+	expect(t, err, nil)
+	expect(t, appRaw, []string{"-v", "exec", "-af", "--output=json", "target", "-x"})
+	expect(t, cmdRaw, []string{"-af", "--output=json", "target", "-x"})
+	expect(t, cmdArgs, []string{"target", "-x"})
+}
+
+func TestContext_CheckRequiredFlags_RequiredWhen(t *testing.T) {
+	newApp := func() *App {
+		return &App{
+			Writer: ioutil.Discard,
+			Flags: []Flag{
+				&BoolFlag{Name: "tls"},
+				&PathFlag{
+					Name: "tls-cert",
+					RequiredWhen: func(c *Context) bool {
+						return c.Bool("tls")
+					},
+				},
+			},
+			Action: func(c *Context) error { return nil },
+		}
+	}
+
+	expect(t, newApp().Run([]string{"app"}), nil)
+	expect(t, newApp().Run([]string{"app", "--tls", "--tls-cert", "cert.pem"}), nil)
+
+	err := newApp().Run([]string{"app", "--tls"})
+	expect(t, err, &RequiredFlagError{Flags: []string{"tls-cert"}})
+
+	app := newApp()
+	app.Flags[1] = &requiredWhenTLSFlag{&StringFlag{Name: "tls-key"}}
+	err = app.Run([]string{"app", "--tls"})
+	expect(t, err, &RequiredFlagError{Flags: []string{"tls-key"}})
+}
+
+// requiredWhenTLSFlag is a user-defined flag that is required when the
+// "tls" flag is set
+type requiredWhenTLSFlag struct {
+	Flag
+}
+
+func (f *requiredWhenTLSFlag) IsRequiredWhen(c *Context) bool {
+	return c.Bool("tls")
+}
+
 func TestContext_LocalFlagNames(t *testing.T) {
 	set := flag.NewFlagSet("test", 0)
 	set.Bool("one-flag", false, "doc")
@@ -290,6 +708,44 @@ func TestContext_FlagNames(t *testing.T) {
 	expect(t, actualFlags, []string{"one-flag", "top-flag", "two-flag"})
 }
 
+func TestContext_FlagNamesIncludesEnvVars(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_REGION", "eu-west-1")
+	_ = os.Setenv("APP_DEBUG", "true")
+
+	var localFlags, allFlags []string
+	app := &App{
+		Flags: []Flag{
+			&BoolFlag{Name: "debug", EnvVars: []string{"APP_DEBUG"}},
+			&BoolFlag{Name: "quiet"},
+		},
+		Commands: []*Command{
+			{
+				Name: "deploy",
+				Flags: []Flag{
+					&StringFlag{Name: "region", Aliases: []string{"r"}, EnvVars: []string{"APP_REGION"}},
+					&StringFlag{Name: "zone"},
+					&IntFlag{Name: "replicas"},
+				},
+				Action: func(c *Context) error {
+					localFlags = c.LocalFlagNames()
+					allFlags = c.FlagNames()
+					return nil
+				},
+			},
+		},
+	}
+
+	err := app.Run([]string{"run", "deploy", "--replicas", "3"})
+	expect(t, err, nil)
+
+	sort.Strings(localFlags)
+	sort.Strings(allFlags)
+	expect(t, localFlags, []string{"r", "region", "replicas"})
+	expect(t, allFlags, []string{"debug", "r", "region", "replicas"})
+}
+
 func TestContext_Lineage(t *testing.T) {
 	set := flag.NewFlagSet("test", 0)
 	set.Bool("local-flag", false, "doc")
@@ -306,6 +762,47 @@ func TestContext_Lineage(t *testing.T) {
 	expect(t, lineage[1], parentCtx)
 }
 
+func TestContext_Parent(t *testing.T) {
+	var rootParent, parent *Context
+	var name string
+	app := &App{
+		Flags: []Flag{&BoolFlag{Name: "debug"}},
+		Action: func(c *Context) error {
+			rootParent = c.Parent()
+			return nil
+		},
+		Commands: []*Command{
+			{
+				Name:  "a",
+				Flags: []Flag{&StringFlag{Name: "name"}},
+				Subcommands: []*Command{
+					{
+						Name: "b",
+						Action: func(c *Context) error {
+							parent = c.Parent()
+							for cur := c; cur != nil; cur = cur.Parent() {
+								if cur.FlagSet().Lookup("name") != nil {
+									name = cur.String("name")
+									break
+								}
+							}
+							return nil
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expect(t, app.Run([]string{"run"}), nil)
+	expect(t, rootParent == nil, true)
+
+	expect(t, app.Run([]string{"run", "--debug", "a", "--name", "parent", "b"}), nil)
+	expect(t, name, "parent")
+	expect(t, parent != nil, true)
+	expect(t, parent.Lineage()[len(parent.Lineage())-1].Bool("debug"), true)
+}
+
 func TestContext_lookupFlagSet(t *testing.T) {
 	set := flag.NewFlagSet("test", 0)
 	set.Bool("local-flag", false, "doc")
@@ -546,6 +1043,14 @@ func TestCheckRequiredFlags(t *testing.T) {
 			},
 			parseInput: []string{"-n", "asd", "-n", "qwe"},
 		},
+		{
+			testCase:              "required_flag_with_only_a_short_name",
+			expectedAnError:       true,
+			expectedErrorContents: []string{"Required flag \"n\" not set"},
+			flags: []Flag{
+				&StringFlag{Name: "n", Required: true},
+			},
+		},
 		{
 			testCase:              "required_flag_with_short_alias_not_printed_on_error",
 			expectedAnError:       true,
@@ -595,3 +1100,167 @@ func TestCheckRequiredFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestContext_NumericAccessors(t *testing.T) {
+	tests := []struct {
+		name   string
+		get    func(*Context, string) interface{}
+		global string
+		local  string
+		want   interface{}
+		zero   interface{}
+	}{
+		{
+			name:   "Int",
+			get:    func(c *Context, name string) interface{} { return c.Int(name) },
+			global: "int", local: "int-local",
+			want: -3, zero: 0,
+		},
+		{
+			name:   "Int64",
+			get:    func(c *Context, name string) interface{} { return c.Int64(name) },
+			global: "int64", local: "int64-local",
+			want: int64(-64), zero: int64(0),
+		},
+		{
+			name:   "Uint",
+			get:    func(c *Context, name string) interface{} { return c.Uint(name) },
+			global: "uint", local: "uint-local",
+			want: uint(7), zero: uint(0),
+		},
+		{
+			name:   "Uint64",
+			get:    func(c *Context, name string) interface{} { return c.Uint64(name) },
+			global: "uint64", local: "uint64-local",
+			want: uint64(1 << 40), zero: uint64(0),
+		},
+		{
+			name:   "Float64",
+			get:    func(c *Context, name string) interface{} { return c.Float64(name) },
+			global: "float64", local: "float64-local",
+			want: 2.5, zero: float64(0),
+		},
+		{
+			name:   "IntSlice",
+			get:    func(c *Context, name string) interface{} { return c.IntSlice(name) },
+			global: "int-slice", local: "int-slice-local",
+			want: []int{1, 2}, zero: []int(nil),
+		},
+		{
+			name:   "Int64Slice",
+			get:    func(c *Context, name string) interface{} { return c.Int64Slice(name) },
+			global: "int64-slice", local: "int64-slice-local",
+			want: []int64{3, 4}, zero: []int64(nil),
+		},
+		{
+			name:   "Float64Slice",
+			get:    func(c *Context, name string) interface{} { return c.Float64Slice(name) },
+			global: "float64-slice", local: "float64-slice-local",
+			want: []float64{0.5, 1.5}, zero: []float64(nil),
+		},
+	}
+
+	var ctx *Context
+	err := (&App{
+		Flags: []Flag{
+			&IntFlag{Name: "int"},
+			&Int64Flag{Name: "int64"},
+			&UintFlag{Name: "uint"},
+			&Uint64Flag{Name: "uint64"},
+			&Float64Flag{Name: "float64"},
+			&IntSliceFlag{Name: "int-slice"},
+			&Int64SliceFlag{Name: "int64-slice"},
+			&Float64SliceFlag{Name: "float64-slice"},
+		},
+		Commands: []*Command{{
+			Name: "cmd",
+			Flags: []Flag{
+				&IntFlag{Name: "int-local"},
+				&Int64Flag{Name: "int64-local"},
+				&UintFlag{Name: "uint-local"},
+				&Uint64Flag{Name: "uint64-local"},
+				&Float64Flag{Name: "float64-local"},
+				&IntSliceFlag{Name: "int-slice-local"},
+				&Int64SliceFlag{Name: "int64-slice-local"},
+				&Float64SliceFlag{Name: "float64-slice-local"},
+			},
+			Action: func(c *Context) error {
+				ctx = c
+				return nil
+			},
+		}},
+	}).Run([]string{
+		"run",
+		"--int", "-3", "--int64", "-64", "--uint", "7", "--uint64", "1099511627776", "--float64", "2.5",
+		"--int-slice", "1", "--int-slice", "2", "--int64-slice", "3", "--int64-slice", "4", "--float64-slice", "0.5,1.5",
+		"cmd",
+		"--int-local", "-3", "--int64-local", "-64", "--uint-local", "7", "--uint64-local", "1099511627776", "--float64-local", "2.5",
+		"--int-slice-local", "1", "--int-slice-local", "2", "--int64-slice-local", "3", "--int64-slice-local", "4", "--float64-slice-local", "0.5,1.5",
+	})
+	expect(t, err, nil)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expect(t, test.get(ctx, test.local), test.want)
+			expect(t, test.get(ctx, test.global), test.want)
+			expect(t, test.get(ctx, "missing"), test.zero)
+		})
+	}
+}
+
+func TestContext_NumOccurrences(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		flag string
+		want int
+	}{
+		{name: "repeated", args: []string{"run", "--x", "a", "--x", "b"}, flag: "x", want: 2},
+		{name: "comma separated", args: []string{"run", "--x", "a,b"}, flag: "x", want: 1},
+		{name: "with equals", args: []string{"run", "--x=a", "-x", "b", "--x=c"}, flag: "x", want: 3},
+		{name: "aliases", args: []string{"run", "-v", "--x", "a", "-v"}, flag: "verbose", want: 2},
+		{name: "value that looks like a flag", args: []string{"run", "--x", "-v", "-v"}, flag: "v", want: 1},
+		{name: "short options", args: []string{"run", "-vv", "--x", "a"}, flag: "v", want: 2},
+		{name: "after terminator", args: []string{"run", "-v", "--", "-v"}, flag: "v", want: 1},
+		{name: "not given", args: []string{"run", "--x", "a"}, flag: "verbose", want: 0},
+		{name: "undefined", args: []string{"run"}, flag: "missing", want: 0},
+		{name: "parent context", args: []string{"run", "-v", "-v", "cmd", "--y", "c"}, flag: "v", want: 2},
+		{name: "command context", args: []string{"run", "cmd", "--y", "c", "--y", "d", "arg"}, flag: "y", want: 2},
+		{name: "negative number argument", args: []string{"run", "-v", "-5", "-v"}, flag: "v", want: 1},
+		{name: "raw parsing", args: []string{"run", "raw", "--y", "c", "--y", "d"}, flag: "y", want: 2},
+		{name: "persistent flag", args: []string{"run", "--z", "1", "--z", "2", "cmd"}, flag: "z", want: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := -1
+			action := func(c *Context) error {
+				got = c.NumOccurrences(test.flag)
+				return nil
+			}
+			app := &App{
+				UseShortOptionHandling: true,
+				Flags: []Flag{
+					&StringSliceFlag{Name: "x"},
+					&BoolFlag{Name: "verbose", Aliases: []string{"v"}},
+				},
+				PersistentFlags: []Flag{&IntFlag{Name: "z"}},
+				Action:          action,
+				Commands: []*Command{{
+					Name:   "cmd",
+					Flags:  []Flag{&StringSliceFlag{Name: "y"}},
+					Action: action,
+				}, {
+					Name:          "raw",
+					UseRawParsing: true,
+					Flags:         []Flag{&StringSliceFlag{Name: "y"}},
+					Action:        action,
+				}},
+			}
+
+			err := app.Run(test.args)
+			expect(t, err, nil)
+			expect(t, got, test.want)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -14,6 +15,11 @@ var OsExiter = os.Exit
 // implementing the io.Writer interface and defaults to os.Stderr.
 var ErrWriter io.Writer = os.Stderr
 
+// ErrSkipAction can be returned from a Before function, also wrapped, to
+// skip the Action and any subcommands without failing. The After function is
+// still run and the App or Command returns nil instead of ErrSkipAction.
+var ErrSkipAction = errors.New("skip action")
+
 // MultiError is an error that wraps multiple errors.
 type MultiError interface {
 	error
@@ -52,21 +58,85 @@ type requiredFlagsErr interface {
 	getMissingFlags() []string
 }
 
-type errRequiredFlags struct {
-	missingFlags []string
+// RequiredFlagError is returned by an App or a Command when required flags
+// are not set. Flags holds the names of the missing flags.
+type RequiredFlagError struct {
+	Flags []string
 }
 
-func (e *errRequiredFlags) Error() string {
-	numberOfMissingFlags := len(e.missingFlags)
+func (e *RequiredFlagError) Error() string {
+	numberOfMissingFlags := len(e.Flags)
 	if numberOfMissingFlags == 1 {
-		return fmt.Sprintf("Required flag %q not set", e.missingFlags[0])
+		return fmt.Sprintf("Required flag %q not set", e.Flags[0])
 	}
-	joinedMissingFlags := strings.Join(e.missingFlags, ", ")
+	joinedMissingFlags := strings.Join(e.Flags, ", ")
 	return fmt.Sprintf("Required flags %q not set", joinedMissingFlags)
 }
 
-func (e *errRequiredFlags) getMissingFlags() []string {
-	return e.missingFlags
+func (e *RequiredFlagError) getMissingFlags() []string {
+	return e.Flags
+}
+
+// UnknownFlagError is returned by an App or a Command when the arguments
+// hold a flag that is not defined. Flag is the flag as it was given, e.g.
+// "--verbose", and Command the name of the command it was given to.
+type UnknownFlagError struct {
+	Flag    string
+	Command string
+}
+
+func (e *UnknownFlagError) Error() string {
+	return fmt.Sprintf("unknown flag %s for command %s", e.Flag, e.Command)
+}
+
+// MissingValueError is returned by an App or a Command when a flag that
+// takes a value is the last argument. Flag is the name of the flag.
+type MissingValueError struct {
+	Flag string
+}
+
+func (e *MissingValueError) Error() string {
+	return fmt.Sprintf("flag needs an argument: -%s", e.Flag)
+}
+
+// InvalidValueError is returned by an App or a Command when the value given
+// to a flag cannot be parsed as the type of the flag, e.g. "x" for an
+// IntFlag. Flag is the name of the flag and Value the value given to it.
+type InvalidValueError struct {
+	Flag  string
+	Value string
+
+	// err is the error of the flag package, which Error returns as it is
+	err error
+	// cause is the error of the value of the flag
+	cause error
+}
+
+func (e *InvalidValueError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the error returned by the Set method of the value of the
+// flag
+func (e *InvalidValueError) Unwrap() error {
+	return e.cause
+}
+
+// InvalidFlagsError is returned by an App or a Command when its flags were
+// given in a way that cannot be reconciled, e.g. two names of the same flag.
+// Command is the name of the App or Command and Err the cause.
+type InvalidFlagsError struct {
+	Command string
+	Err     error
+}
+
+func (e *InvalidFlagsError) Error() string {
+	return fmt.Sprintf("invalid flags for %s: %s", e.Command, e.Err)
+}
+
+// Unwrap returns the cause of the error
+func (e *InvalidFlagsError) Unwrap() error {
+	return e.Err
 }
 
 // ErrorFormatter is the interface that will suitably format the error output
@@ -3,7 +3,9 @@ package cli
 import (
 	"bytes"
 	"errors"
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"testing"
 )
 
@@ -45,6 +47,30 @@ func TestHandleExitCoder_ExitCoder(t *testing.T) {
 	expect(t, called, true)
 }
 
+func TestHandleExitCoder_ExitCoderWithEmptyMessage(t *testing.T) {
+	exitCode := 0
+	called := false
+
+	OsExiter = func(rc int) {
+		if !called {
+			exitCode = rc
+			called = true
+		}
+	}
+
+	oldWriter := ErrWriter
+	defer func() { OsExiter = fakeOsExiter; ErrWriter = oldWriter }()
+
+	buf := &bytes.Buffer{}
+	ErrWriter = buf
+
+	HandleExitCoder(Exit("", 4))
+
+	expect(t, exitCode, 4)
+	expect(t, called, true)
+	expect(t, buf.String(), "")
+}
+
 func TestHandleExitCoder_MultiErrorWithExitCoder(t *testing.T) {
 	exitCode := 0
 	called := false
@@ -140,3 +166,83 @@ func TestHandleExitCoder_MultiErrorWithFormat(t *testing.T) {
 	expect(t, called, true)
 	expect(t, ErrWriter.(*bytes.Buffer).String(), "This the format: err1\nThis the format: err2\n")
 }
+
+func TestParseErrorTypes(t *testing.T) {
+	newApp := func() *App {
+		return &App{
+			Writer:    ioutil.Discard,
+			ErrWriter: ioutil.Discard,
+			Commands: []*Command{
+				{
+					Name: "deploy",
+					Flags: []Flag{
+						&IntFlag{Name: "count"},
+						&BoolFlag{Name: "force"},
+						&StringFlag{Name: "env", Required: true},
+					},
+					Action: func(*Context) error { return nil },
+				},
+			},
+		}
+	}
+
+	err := newApp().Run([]string{"app", "deploy", "--verbose"})
+	var unknown *UnknownFlagError
+	expect(t, errors.As(err, &unknown), true)
+	expect(t, unknown.Flag, "--verbose")
+	expect(t, unknown.Command, "deploy")
+
+	err = newApp().Run([]string{"app", "deploy", "--count"})
+	var missing *MissingValueError
+	expect(t, errors.As(err, &missing), true)
+	expect(t, missing.Flag, "count")
+
+	err = newApp().Run([]string{"app", "deploy", "--count", "x"})
+	var invalid *InvalidValueError
+	expect(t, errors.As(err, &invalid), true)
+	expect(t, invalid.Flag, "count")
+	expect(t, invalid.Value, "x")
+	expect(t, errors.Unwrap(invalid) != nil, true)
+
+	err = newApp().Run([]string{"app", "deploy", "--force=maybe"})
+	expect(t, errors.As(err, &invalid), true)
+	expect(t, invalid.Flag, "force")
+	expect(t, invalid.Value, "maybe")
+	expect(t, errors.Unwrap(invalid) != nil, true)
+
+	errBadEnv := errors.New("bad env")
+	app := newApp()
+	app.Commands[0].Flags[2] = &GenericFlag{Name: "env", Value: &testErrorGeneric{err: errBadEnv}}
+	err = app.Run([]string{"app", "deploy", "--env", "prod"})
+	expect(t, errors.As(err, &invalid), true)
+	expect(t, invalid.Flag, "env")
+	expect(t, invalid.Value, "prod")
+	expect(t, errors.Is(err, errBadEnv), true)
+
+	err = newApp().Run([]string{"app", "deploy"})
+	var required *RequiredFlagError
+	expect(t, errors.As(err, &required), true)
+	expect(t, required.Flags, []string{"env"})
+	expect(t, err.Error(), `Required flag "env" not set`)
+}
+
+// TestParseErrorMessages pins the messages of the flag package which
+// parseError relies on to tell unknown flags and missing values apart
+func TestParseErrorMessages(t *testing.T) {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+	set.Int("count", 0, "")
+
+	err := set.Parse([]string{"-verbose"})
+	expect(t, err.Error(), "flag provided but not defined: -verbose")
+
+	err = set.Parse([]string{"-count"})
+	expect(t, err.Error(), "flag needs an argument: -count")
+}
+
+type testErrorGeneric struct {
+	err error
+}
+
+func (g *testErrorGeneric) Set(string) error { return g.err }
+func (g *testErrorGeneric) String() string   { return "" }
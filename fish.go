@@ -39,7 +39,7 @@ func (a *App) writeFishCompletionTemplate(w io.Writer) error {
 	if !a.HideHelp {
 		completions = append(
 			completions,
-			a.prepareFishFlags([]Flag{HelpFlag}, allCommands)...,
+			a.prepareFishFlags([]Flag{a.helpFlag()}, allCommands)...,
 		)
 	}
 
@@ -47,7 +47,7 @@ func (a *App) writeFishCompletionTemplate(w io.Writer) error {
 	if !a.HideVersion {
 		completions = append(
 			completions,
-			a.prepareFishFlags([]Flag{VersionFlag}, allCommands)...,
+			a.prepareFishFlags([]Flag{a.versionFlag()}, allCommands)...,
 		)
 	}
 
@@ -84,10 +84,10 @@ func (a *App) prepareFishCommands(commands []*Command, allCommands *[]string, pr
 				escapeSingleQuotes(command.Usage)))
 		}
 
-		if !command.HideHelp {
+		if !command.HideHelp && !command.HideHelpFlag {
 			completions = append(
 				completions,
-				a.prepareFishFlags([]Flag{HelpFlag}, command.Names())...,
+				a.prepareFishFlags([]Flag{a.helpFlag()}, command.Names())...,
 			)
 		}
 
@@ -119,6 +119,9 @@ func (a *App) prepareFishFlags(flags []Flag, previousCommands []string) []string
 		if !ok {
 			continue
 		}
+		if vf, ok := f.(VisibleFlag); ok && !vf.IsVisible() {
+			continue
+		}
 
 		completion := &strings.Builder{}
 		completion.WriteString(fmt.Sprintf(
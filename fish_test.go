@@ -11,6 +11,10 @@ func TestFishCompletion(t *testing.T) {
 		Name:      "logfile",
 		TakesFile: true,
 	})
+	app.Commands[0].Flags = append(app.Commands[0].Flags, &BoolFlag{
+		Name:   "hidden-command-flag",
+		Hidden: true,
+	})
 
 	// When
 	res, err := app.ToFishCompletion()
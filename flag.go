@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"reflect"
 	"regexp"
 	"runtime"
@@ -44,8 +45,20 @@ var HelpFlag Flag = &BoolFlag{
 	Usage:   "show help",
 }
 
+// ConfirmFlag skips the confirmation prompt of the commands with
+// RequireConfirmation. Set to nil to always prompt.
+var ConfirmFlag Flag = &BoolFlag{
+	Name:  "yes",
+	Usage: "skip the confirmation prompt",
+}
+
 // FlagStringer converts a flag definition to a string. This is used by help
-// to display a flag.
+// to display a flag. Custom implementations can get at the names of the flag
+// through Names and at its usage and value through the DocGenerationFlag
+// interface.
+//
+// The default FlagStringer shows the DefaultText of a flag, when it is not
+// empty, in place of the default computed from its Value.
 var FlagStringer FlagStringFunc = stringifyFlag
 
 // Serializer is used to circumvent the limitations of flag.FlagSet.Set
@@ -61,10 +74,15 @@ var FlagNamePrefixer FlagNamePrefixFunc = prefixedNames
 // details. This is used by the default FlagStringer.
 var FlagEnvHinter FlagEnvHintFunc = withEnvHint
 
-// FlagFileHinter annotates flag help message with the environment variable
-// details. This is used by the default FlagStringer.
+// FlagFileHinter annotates flag help message with the file path details.
+// This is used by the default FlagStringer.
 var FlagFileHinter FlagFileHintFunc = withFileHint
 
+// EnvSliceDelimiter is the delimiter used to split the value of a slice or
+// map flag read from the environment or a file into its elements, e.g. "\n"
+// for newline delimited values. Empty elements are dropped.
+var EnvSliceDelimiter = ","
+
 // FlagsByName is a slice of Flag.
 type FlagsByName []Flag
 
@@ -78,7 +96,7 @@ func (f FlagsByName) Less(i, j int) bool {
 	} else if len(f[i].Names()) == 0 {
 		return true
 	}
-	return lexicographicLess(f[i].Names()[0], f[j].Names()[0])
+	return lexicographicLess(strings.TrimLeft(f[i].Names()[0], "-"), strings.TrimLeft(f[j].Names()[0], "-"))
 }
 
 func (f FlagsByName) Swap(i, j int) {
@@ -104,6 +122,15 @@ type RequiredFlag interface {
 	IsRequired() bool
 }
 
+// RequiredWhenFlag is an interface that allows a flag to be required
+// depending on the context, e.g. only when another flag is set
+type RequiredWhenFlag interface {
+	Flag
+
+	// IsRequiredWhen returns true if the flag is required in the context
+	IsRequiredWhen(*Context) bool
+}
+
 // DocGenerationFlag is an interface that allows documentation generation for the flag
 type DocGenerationFlag interface {
 	Flag
@@ -127,18 +154,87 @@ type VisibleFlag interface {
 	IsVisible() bool
 }
 
+// CategorizableFlag is an interface that allows a flag to be listed under a
+// category in help
+type CategorizableFlag interface {
+	Flag
+
+	// GetCategory returns the category of the flag in help
+	GetCategory() string
+}
+
+// DeprecatableFlag is an interface that allows a flag to be deprecated, so
+// that a warning is printed when it is used and help notes it
+type DeprecatableFlag interface {
+	Flag
+
+	// GetDeprecated returns the deprecation notice of the flag, or an empty
+	// string if it is not deprecated
+	GetDeprecated() string
+}
+
+// SensitiveFlag is an interface that allows a flag to have its value masked
+// where values are reported, e.g. by Context.AllFlags
+type SensitiveFlag interface {
+	Flag
+
+	// IsSensitive returns true if the value of the flag is masked, otherwise false
+	IsSensitive() bool
+}
+
+// CompletionFlag is an interface that allows flags to complete their value
+// during shell completion
+type CompletionFlag interface {
+	Flag
+
+	// GetCompletionFunc returns the function used to complete the flag's
+	// value, or nil if there is none
+	GetCompletionFunc() FlagCompleteFunc
+}
+
 func flagSet(name string, flags []Flag) (*flag.FlagSet, error) {
 	set := flag.NewFlagSet(name, flag.ContinueOnError)
 
+	// flag.FlagSet panics when a name is defined twice, so catch
+	// duplicated names and aliases before applying any flag
+	seen := map[string]bool{}
+	for _, f := range flags {
+		for _, n := range f.Names() {
+			if seen[n] {
+				return nil, fmt.Errorf("flag name %q is used by more than one flag of %s", n, name)
+			}
+			seen[n] = true
+		}
+	}
+
 	for _, f := range flags {
 		if err := f.Apply(set); err != nil {
 			return nil, err
 		}
+		shareFlagValue(set, f.Names())
 	}
 	set.SetOutput(ioutil.Discard)
 	return set, nil
 }
 
+// shareFlagValue makes the names of a flag in set use the value of its first
+// name, so that a value set through any of the names is seen through all of
+// them right away, while parsing, and not only once normalizeFlags ran
+func shareFlagValue(set *flag.FlagSet, names []string) {
+	if len(names) < 2 {
+		return
+	}
+	primary := set.Lookup(names[0])
+	if primary == nil {
+		return
+	}
+	for _, name := range names[1:] {
+		if ff := set.Lookup(name); ff != nil {
+			ff.Value = primary.Value
+		}
+	}
+}
+
 func copyFlag(name string, ff *flag.Flag, set *flag.FlagSet) {
 	switch ff.Value.(type) {
 	case Serializer:
@@ -148,6 +244,31 @@ func copyFlag(name string, ff *flag.Flag, set *flag.FlagSet) {
 	}
 }
 
+// markFlagSet marks ff as set in set, like set.Set does, without setting
+// its value
+func markFlagSet(set *flag.FlagSet, ff *flag.Flag) {
+	value := ff.Value
+	ff.Value = unsetValue{}
+	_ = set.Set(ff.Name, "")
+	ff.Value = value
+}
+
+// unsetValue is a flag.Value that ignores what it is set to
+type unsetValue struct{}
+
+func (unsetValue) Set(string) error { return nil }
+func (unsetValue) String() string   { return "" }
+
+// NormalizeFlags synchronizes the names of each of flags in a parsed set: the
+// value set through one of the names of a flag is propagated to all of its
+// other names, so that looking the flag up by any name gives the same value.
+// The names of the flags in the flag sets built by this package already share
+// one value, for those normalizing marks the other names as set as well.
+// It errors if more than one name of the same flag was set.
+func NormalizeFlags(flags []Flag, set *flag.FlagSet) error {
+	return normalizeFlags(flags, set)
+}
+
 func normalizeFlags(flags []Flag, set *flag.FlagSet) error {
 	visited := make(map[string]bool)
 	set.Visit(func(f *flag.Flag) {
@@ -173,9 +294,16 @@ func normalizeFlags(flags []Flag, set *flag.FlagSet) error {
 		}
 		for _, name := range parts {
 			name = strings.Trim(name, " ")
-			if !visited[name] {
-				copyFlag(name, ff, set)
+			if visited[name] {
+				continue
+			}
+			// a name sharing the value of ff already has the value, which
+			// setting again would apply twice, e.g. append it to a slice
+			if alias := set.Lookup(name); alias != nil && containsValue([]flag.Value{ff.Value}, alias.Value) {
+				markFlagSet(set, alias)
+				continue
 			}
+			copyFlag(name, ff, set)
 		}
 	}
 	return nil
@@ -239,20 +367,25 @@ func prefixedNames(names []string, placeholder string) string {
 func withEnvHint(envVars []string, str string) string {
 	envText := ""
 	if envVars != nil && len(envVars) > 0 {
-		prefix := "$"
-		suffix := ""
-		sep := ", $"
-		if runtime.GOOS == "windows" {
-			prefix = "%"
-			suffix = "%"
-			sep = "%, %"
-		}
-
-		envText = fmt.Sprintf(" [%s%s%s]", prefix, strings.Join(envVars, sep), suffix)
+		envText = fmt.Sprintf(" [%s]", envVarNames(envVars))
 	}
 	return str + envText
 }
 
+// envVarNames returns envVars as they are referred to in the shell of the
+// platform, e.g. "$HOME, $USER"
+func envVarNames(envVars []string) string {
+	prefix := "$"
+	suffix := ""
+	sep := ", $"
+	if runtime.GOOS == "windows" {
+		prefix = "%"
+		suffix = "%"
+		sep = "%, %"
+	}
+	return prefix + strings.Join(envVars, sep) + suffix
+}
+
 func flagNames(name string, aliases []string) []string {
 	var ret []string
 
@@ -278,6 +411,41 @@ func flagStringSliceField(f Flag, name string) []string {
 	return []string{}
 }
 
+// flagCategory returns the help category of f, or an empty string if f has
+// no category
+func flagCategory(f Flag) string {
+	if cf, ok := f.(CategorizableFlag); ok {
+		return cf.GetCategory()
+	}
+	return ""
+}
+
+// flagDeprecation returns the deprecation notice of f, or an empty string
+// if f is not deprecated
+func flagDeprecation(f Flag) string {
+	if df, ok := f.(DeprecatableFlag); ok {
+		return df.GetDeprecated()
+	}
+	return ""
+}
+
+// withFlagHints annotates the help message str of f with its deprecation
+// notice, and unless hints is false with its environment variables and file
+// path using FlagEnvHinter and FlagFileHinter
+func withFlagHints(f Flag, str string, hints bool) string {
+	if deprecated := flagDeprecation(f); deprecated != "" {
+		str += fmt.Sprintf(" (deprecated: %s)", deprecated)
+	}
+	if !hints {
+		return str
+	}
+	str = FlagEnvHinter(flagStringSliceField(f, "EnvVars"), str)
+	if filePath := flagValue(f).FieldByName("FilePath"); filePath.IsValid() {
+		str = FlagFileHinter(filePath.String(), str)
+	}
+	return str
+}
+
 func withFileHint(filePath, str string) string {
 	fileText := ""
 	if filePath != "" {
@@ -299,21 +467,29 @@ func formatDefault(format string) string {
 }
 
 func stringifyFlag(f Flag) string {
+	return stringifyFlagHints(f, true)
+}
+
+// stringifyFlagHints returns the help line of f like stringifyFlag, leaving
+// out the environment variable and file hints unless hints is set
+func stringifyFlagHints(f Flag, hints bool) string {
 	fv := flagValue(f)
 
 	switch f := f.(type) {
 	case *IntSliceFlag:
-		return withEnvHint(flagStringSliceField(f, "EnvVars"),
-			stringifyIntSliceFlag(f))
+		return withFlagHints(f, stringifyIntSliceFlag(f), hints)
 	case *Int64SliceFlag:
-		return withEnvHint(flagStringSliceField(f, "EnvVars"),
-			stringifyInt64SliceFlag(f))
+		return withFlagHints(f, stringifyInt64SliceFlag(f), hints)
 	case *Float64SliceFlag:
-		return withEnvHint(flagStringSliceField(f, "EnvVars"),
-			stringifyFloat64SliceFlag(f))
+		return withFlagHints(f, stringifyFloat64SliceFlag(f), hints)
 	case *StringSliceFlag:
-		return withEnvHint(flagStringSliceField(f, "EnvVars"),
-			stringifyStringSliceFlag(f))
+		return withFlagHints(f, stringifyStringSliceFlag(f), hints)
+	case *StringMapFlag:
+		return withFlagHints(f, stringifyStringMapFlag(f), hints)
+	}
+
+	if sf, ok := f.(*StringFlag); ok && sf.EnvOnly {
+		return stringifyEnvOnlyFlag(sf, hints)
 	}
 
 	placeholder, usage := unquoteUsage(fv.FieldByName("Usage").String())
@@ -328,6 +504,14 @@ func stringifyFlag(f Flag) string {
 		if val.Kind() == reflect.String && val.String() != "" {
 			defaultValueString = fmt.Sprintf(formatDefault("%q"), val.String())
 		}
+
+		// there is no default to show for an unset pointer or slice Value
+		switch val.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+			if val.IsNil() {
+				defaultValueString = ""
+			}
+		}
 	}
 
 	helpText := fv.FieldByName("DefaultText")
@@ -340,14 +524,39 @@ func stringifyFlag(f Flag) string {
 		defaultValueString = ""
 	}
 
+	// a count flag is given without a value, like a bool flag
+	if _, ok := f.(*CountFlag); ok {
+		needsPlaceholder = false
+	}
+
 	if needsPlaceholder && placeholder == "" {
 		placeholder = defaultPlaceholder
 	}
 
 	usageWithDefault := strings.TrimSpace(usage + defaultValueString)
 
-	return withEnvHint(flagStringSliceField(f, "EnvVars"),
-		fmt.Sprintf("%s\t%s", prefixedNames(f.Names(), placeholder), usageWithDefault))
+	return withFlagHints(f, fmt.Sprintf("%s\t%s", prefixedNames(f.Names(), placeholder), usageWithDefault), hints)
+}
+
+// stringifyEnvOnlyFlag returns the help line of an env only flag, which
+// cannot be given on the command line, so it shows the environment
+// variables of the flag, or its file, in place of its names. Its value is
+// likely a secret, so it is not shown.
+func stringifyEnvOnlyFlag(f *StringFlag, hints bool) string {
+	_, usage := unquoteUsage(f.Usage)
+	usage = strings.TrimSpace(usage + " (environment or file only)")
+	if deprecated := flagDeprecation(f); deprecated != "" {
+		usage += fmt.Sprintf(" (deprecated: %s)", deprecated)
+	}
+
+	if len(f.EnvVars) == 0 {
+		return fmt.Sprintf("%s\t%s", f.FilePath, usage)
+	}
+	str := fmt.Sprintf("%s\t%s", envVarNames(f.EnvVars), usage)
+	if hints {
+		str = FlagFileHinter(f.FilePath, str)
+	}
+	return str
 }
 
 func stringifyIntSliceFlag(f *IntSliceFlag) string {
@@ -358,6 +567,10 @@ func stringifyIntSliceFlag(f *IntSliceFlag) string {
 		}
 	}
 
+	if f.DefaultText != "" {
+		defaultVals = []string{f.DefaultText}
+	}
+
 	return stringifySliceFlag(f.Usage, f.Names(), defaultVals)
 }
 
@@ -369,6 +582,10 @@ func stringifyInt64SliceFlag(f *Int64SliceFlag) string {
 		}
 	}
 
+	if f.DefaultText != "" {
+		defaultVals = []string{f.DefaultText}
+	}
+
 	return stringifySliceFlag(f.Usage, f.Names(), defaultVals)
 }
 
@@ -381,6 +598,10 @@ func stringifyFloat64SliceFlag(f *Float64SliceFlag) string {
 		}
 	}
 
+	if f.DefaultText != "" {
+		defaultVals = []string{f.DefaultText}
+	}
+
 	return stringifySliceFlag(f.Usage, f.Names(), defaultVals)
 }
 
@@ -394,6 +615,25 @@ func stringifyStringSliceFlag(f *StringSliceFlag) string {
 		}
 	}
 
+	if f.DefaultText != "" {
+		defaultVals = []string{f.DefaultText}
+	}
+
+	return stringifySliceFlag(f.Usage, f.Names(), defaultVals)
+}
+
+func stringifyStringMapFlag(f *StringMapFlag) string {
+	var defaultVals []string
+	if f.Value != nil {
+		for _, pair := range f.Value.pairs() {
+			defaultVals = append(defaultVals, strconv.Quote(pair))
+		}
+	}
+
+	if f.DefaultText != "" {
+		defaultVals = []string{f.DefaultText}
+	}
+
 	return stringifySliceFlag(f.Usage, f.Names(), defaultVals)
 }
 
@@ -416,6 +656,46 @@ func stringifySliceFlag(usage string, names, defaultVals []string) string {
 	return fmt.Sprintf("%s\t%s%s", prefixedNames(names, placeholder), usageWithDefault, multiInputString)
 }
 
+// hasFlagName reports whether any of the flags uses one of the names of fl
+func hasFlagName(flags []Flag, fl Flag) bool {
+	for _, existing := range flags {
+		for _, name := range existing.Names() {
+			for _, other := range fl.Names() {
+				if name == other {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// helpFlagFor returns the help flag to add to flags: helpFlag itself, or a
+// copy of it without the names that flags already use, e.g. without -h for
+// a command that defines its own -h, so that --help keeps working. It
+// returns nil when flags use all the names of helpFlag, or some of them and
+// helpFlag is not a *BoolFlag that can be copied.
+func helpFlagFor(flags []Flag, helpFlag Flag) Flag {
+	var names []string
+	for _, name := range helpFlag.Names() {
+		if !hasFlagName(flags, &BoolFlag{Name: name}) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == len(helpFlag.Names()) {
+		return helpFlag
+	}
+
+	bf, ok := helpFlag.(*BoolFlag)
+	if !ok || len(names) == 0 {
+		return nil
+	}
+	copied := *bf
+	copied.Name, copied.Aliases = names[0], names[1:]
+	return &copied
+}
+
 func hasFlag(flags []Flag, fl Flag) bool {
 	for _, existing := range flags {
 		if fl == existing {
@@ -426,17 +706,50 @@ func hasFlag(flags []Flag, fl Flag) bool {
 	return false
 }
 
-func flagFromEnvOrFile(envVars []string, filePath string) (val string, ok bool) {
+// splitEnvSlice splits a value read from the environment or a file on
+// EnvSliceDelimiter, trimming the elements and dropping empty ones.
+func splitEnvSlice(val string) []string {
+	var parts []string
+	for _, s := range strings.Split(val, EnvSliceDelimiter) {
+		if s = strings.TrimSpace(s); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return parts
+}
+
+// flagFromEnvOrFile returns the value of the first of envVars that is set or
+// else the content of the first of the comma separated filePath candidates
+// that exists. Files that do not exist are skipped, but an error is returned
+// for a file that exists and cannot be read.
+func flagFromEnvOrFile(envVars []string, filePath string) (val string, ok bool, err error) {
+	val, _, ok, err = flagFromEnvOrFileWithSource(envVars, filePath)
+	return val, ok, err
+}
+
+// flagFromEnvOrFileWithSource is like flagFromEnvOrFile but also returns the
+// name of the environment variable or the path of the file the value was
+// read from.
+func flagFromEnvOrFileWithSource(envVars []string, filePath string) (val string, source string, ok bool, err error) {
 	for _, envVar := range envVars {
 		envVar = strings.TrimSpace(envVar)
 		if val, ok := syscall.Getenv(envVar); ok {
-			return val, true
+			return val, envVar, true, nil
 		}
 	}
 	for _, fileVar := range strings.Split(filePath, ",") {
-		if data, err := ioutil.ReadFile(fileVar); err == nil {
-			return string(data), true
+		fileVar = strings.TrimSpace(fileVar)
+		if fileVar == "" {
+			continue
+		}
+		data, err := ioutil.ReadFile(fileVar)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", fileVar, false, err
 		}
+		return string(data), fileVar, true, nil
 	}
-	return "", false
+	return "", "", false, nil
 }
@@ -4,21 +4,80 @@ import (
 	"flag"
 	"fmt"
 	"strconv"
+	"strings"
 )
 
+// boolValue wraps a bool to satisfy flag.Value, accepting the words of
+// parseBool in addition to the values accepted by strconv.ParseBool
+type boolValue bool
+
+func newBoolValue(val bool, p *bool) *boolValue {
+	*p = val
+	return (*boolValue)(p)
+}
+
+// Set parses the value as a bool
+func (b *boolValue) Set(value string) error {
+	parsed, err := parseBool(value)
+	if err != nil {
+		return err
+	}
+	*b = boolValue(parsed)
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (b *boolValue) String() string {
+	if b == nil {
+		return "false"
+	}
+	return strconv.FormatBool(bool(*b))
+}
+
+// Get returns the bool set by this flag
+func (b *boolValue) Get() interface{} {
+	return bool(*b)
+}
+
+// IsBoolFlag lets the flag be given without a value
+func (b *boolValue) IsBoolFlag() bool {
+	return true
+}
+
+// parseBool parses "yes", "on", "no" and "off" case-insensitively, and
+// otherwise falls back to strconv.ParseBool
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "on":
+		return true, nil
+	case "no", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
 // BoolFlag is a flag with type bool
 type BoolFlag struct {
-	Name        string
-	Aliases     []string
-	Usage       string
-	EnvVars     []string
-	FilePath    string
-	Required    bool
-	Hidden      bool
-	Value       bool
-	DefaultText string
-	Destination *bool
-	HasBeenSet  bool
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	Value        bool
+	DefaultText  string
+	Destination  *bool
+	HasBeenSet   bool
+
+	// EmptyEnvMeansTrue sets the flag to true when one of its EnvVars is
+	// set to the empty string, or its file is empty, which otherwise leaves
+	// the flag unset
+	EmptyEnvMeansTrue bool
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -42,6 +101,11 @@ func (f *BoolFlag) IsRequired() bool {
 	return f.Required
 }
 
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *BoolFlag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
 // TakesValue returns true of the flag takes a value, otherwise false
 func (f *BoolFlag) TakesValue() bool {
 	return false
@@ -63,11 +127,30 @@ func (f *BoolFlag) IsVisible() bool {
 	return !f.Hidden
 }
 
+// GetDeprecated returns the deprecation notice of the flag
+func (f *BoolFlag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *BoolFlag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *BoolFlag) IsSensitive() bool {
+	return f.Sensitive
+}
+
 // Apply populates the flag given the flag set and environment
 func (f *BoolFlag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
 		if val != "" {
-			valBool, err := strconv.ParseBool(val)
+			valBool, err := parseBool(val)
 
 			if err != nil {
 				return fmt.Errorf("could not parse %q as bool value for flag %s: %s", val, f.Name, err)
@@ -75,15 +158,18 @@ func (f *BoolFlag) Apply(set *flag.FlagSet) error {
 
 			f.Value = valBool
 			f.HasBeenSet = true
+		} else if f.EmptyEnvMeansTrue {
+			f.Value = true
+			f.HasBeenSet = true
 		}
 	}
 
 	for _, name := range f.Names() {
 		if f.Destination != nil {
-			set.BoolVar(f.Destination, name, f.Value, f.Usage)
+			set.Var(newBoolValue(f.Value, f.Destination), name, f.Usage)
 			continue
 		}
-		set.Bool(name, f.Value, f.Usage)
+		set.Var(newBoolValue(f.Value, new(bool)), name, f.Usage)
 	}
 
 	return nil
@@ -98,6 +184,24 @@ func (c *Context) Bool(name string) bool {
 	return false
 }
 
+// BoolOr looks up the value of a BoolFlag, returns fallback
+// if it was not set on the command line, from the environment or from a file
+func (c *Context) BoolOr(name string, fallback bool) bool {
+	if !c.IsSet(name) {
+		return fallback
+	}
+	return c.Bool(name)
+}
+
+// TryBool looks up the value of a BoolFlag, also reporting whether a flag
+// named name was found, unlike Bool which returns false for unknown names
+func (c *Context) TryBool(name string) (bool, bool) {
+	if fs := c.lookupFlagSet(name); fs != nil {
+		return lookupBool(name, fs), true
+	}
+	return false, false
+}
+
 func lookupBool(name string, set *flag.FlagSet) bool {
 	f := set.Lookup(name)
 	if f != nil {
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+// countValue wraps an int counting the occurrences of a flag to satisfy
+// flag.Value
+type countValue int
+
+func newCountValue(val int, p *int) *countValue {
+	*p = val
+	return (*countValue)(p)
+}
+
+// Set increments the count when the flag is given without a value, and
+// otherwise sets the count to the value, e.g. 3 for --verbose=3
+func (c *countValue) Set(value string) error {
+	if value == "true" {
+		*c++
+		return nil
+	}
+
+	parsed, err := strconv.ParseInt(value, 0, 64)
+	if err != nil {
+		return err
+	}
+	*c = countValue(parsed)
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (c *countValue) String() string {
+	if c == nil {
+		return "0"
+	}
+	return strconv.Itoa(int(*c))
+}
+
+// Get returns the count of this flag
+func (c *countValue) Get() interface{} {
+	return int(*c)
+}
+
+// IsBoolFlag lets the flag be given without a value
+func (c *countValue) IsBoolFlag() bool {
+	return true
+}
+
+// CountFlag is a flag with type int counting how often it is given, e.g.
+// 2 for --verbose --verbose, starting from Value. An explicit value, as in
+// --verbose=3, sets the count. The count is read with Context.Int.
+type CountFlag struct {
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	Value        int
+	DefaultText  string
+	Destination  *int
+	HasBeenSet   bool
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *CountFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *CountFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *CountFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *CountFlag) IsRequired() bool {
+	return f.Required
+}
+
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *CountFlag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *CountFlag) TakesValue() bool {
+	return false
+}
+
+// GetUsage returns the usage string for the flag
+func (f *CountFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *CountFlag) GetValue() string {
+	return ""
+}
+
+// IsVisible returns true if the flag is not hidden, otherwise false
+func (f *CountFlag) IsVisible() bool {
+	return !f.Hidden
+}
+
+// GetDeprecated returns the deprecation notice of the flag
+func (f *CountFlag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *CountFlag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *CountFlag) IsSensitive() bool {
+	return f.Sensitive
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *CountFlag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		if val != "" {
+			valInt, err := strconv.ParseInt(val, 0, 64)
+
+			if err != nil {
+				return fmt.Errorf("could not parse %q as count value for flag %s: %s", val, f.Name, err)
+			}
+
+			f.Value = int(valInt)
+			f.HasBeenSet = true
+		}
+	}
+
+	// all names share one value, so that every occurrence is counted
+	dest := f.Destination
+	if dest == nil {
+		dest = new(int)
+	}
+	value := newCountValue(f.Value, dest)
+	for _, name := range f.Names() {
+		set.Var(value, name, f.Usage)
+	}
+
+	return nil
+}
@@ -8,17 +8,21 @@ import (
 
 // DurationFlag is a flag with type time.Duration (see https://golang.org/pkg/time/#ParseDuration)
 type DurationFlag struct {
-	Name        string
-	Aliases     []string
-	Usage       string
-	EnvVars     []string
-	FilePath    string
-	Required    bool
-	Hidden      bool
-	Value       time.Duration
-	DefaultText string
-	Destination *time.Duration
-	HasBeenSet  bool
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	Value        time.Duration
+	DefaultText  string
+	Destination  *time.Duration
+	HasBeenSet   bool
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -42,6 +46,11 @@ func (f *DurationFlag) IsRequired() bool {
 	return f.Required
 }
 
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *DurationFlag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
 // TakesValue returns true of the flag takes a value, otherwise false
 func (f *DurationFlag) TakesValue() bool {
 	return true
@@ -63,9 +72,28 @@ func (f *DurationFlag) IsVisible() bool {
 	return !f.Hidden
 }
 
+// GetDeprecated returns the deprecation notice of the flag
+func (f *DurationFlag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *DurationFlag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *DurationFlag) IsSensitive() bool {
+	return f.Sensitive
+}
+
 // Apply populates the flag given the flag set and environment
 func (f *DurationFlag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
 		if val != "" {
 			valDuration, err := time.ParseDuration(val)
 
@@ -97,6 +125,24 @@ func (c *Context) Duration(name string) time.Duration {
 	return 0
 }
 
+// DurationOr looks up the value of a DurationFlag, returns fallback
+// if it was not set on the command line, from the environment or from a file
+func (c *Context) DurationOr(name string, fallback time.Duration) time.Duration {
+	if !c.IsSet(name) {
+		return fallback
+	}
+	return c.Duration(name)
+}
+
+// TryDuration looks up the value of a DurationFlag, also reporting whether a flag
+// named name was found, unlike Duration which returns 0 for unknown names
+func (c *Context) TryDuration(name string) (time.Duration, bool) {
+	if fs := c.lookupFlagSet(name); fs != nil {
+		return lookupDuration(name, fs), true
+	}
+	return 0, false
+}
+
 func lookupDuration(name string, set *flag.FlagSet) time.Duration {
 	f := set.Lookup(name)
 	if f != nil {
@@ -8,17 +8,21 @@ import (
 
 // Float64Flag is a flag with type float64
 type Float64Flag struct {
-	Name        string
-	Aliases     []string
-	Usage       string
-	EnvVars     []string
-	FilePath    string
-	Required    bool
-	Hidden      bool
-	Value       float64
-	DefaultText string
-	Destination *float64
-	HasBeenSet  bool
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	Value        float64
+	DefaultText  string
+	Destination  *float64
+	HasBeenSet   bool
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -42,6 +46,11 @@ func (f *Float64Flag) IsRequired() bool {
 	return f.Required
 }
 
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *Float64Flag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
 // TakesValue returns true of the flag takes a value, otherwise false
 func (f *Float64Flag) TakesValue() bool {
 	return true
@@ -63,11 +72,30 @@ func (f *Float64Flag) IsVisible() bool {
 	return !f.Hidden
 }
 
+// GetDeprecated returns the deprecation notice of the flag
+func (f *Float64Flag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *Float64Flag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *Float64Flag) IsSensitive() bool {
+	return f.Sensitive
+}
+
 // Apply populates the flag given the flag set and environment
 func (f *Float64Flag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
 		if val != "" {
-			valFloat, err := strconv.ParseFloat(val, 10)
+			valFloat, err := strconv.ParseFloat(val, 64)
 
 			if err != nil {
 				return fmt.Errorf("could not parse %q as float64 value for flag %s: %s", val, f.Name, err)
@@ -98,6 +126,24 @@ func (c *Context) Float64(name string) float64 {
 	return 0
 }
 
+// Float64Or looks up the value of a Float64Flag, returns fallback
+// if it was not set on the command line, from the environment or from a file
+func (c *Context) Float64Or(name string, fallback float64) float64 {
+	if !c.IsSet(name) {
+		return fallback
+	}
+	return c.Float64(name)
+}
+
+// TryFloat64 looks up the value of a Float64Flag, also reporting whether a flag
+// named name was found, unlike Float64 which returns 0 for unknown names
+func (c *Context) TryFloat64(name string) (float64, bool) {
+	if fs := c.lookupFlagSet(name); fs != nil {
+		return lookupFloat64(name, fs), true
+	}
+	return 0, false
+}
+
 func lookupFloat64(name string, set *flag.FlagSet) float64 {
 	f := set.Lookup(name)
 	if f != nil {
@@ -29,7 +29,8 @@ func (f *Float64Slice) clone() *Float64Slice {
 	return n
 }
 
-// Set parses the value into a float64 and appends it to the list of values
+// Set parses the value as a comma separated list of float64s and appends
+// them to the list of values
 func (f *Float64Slice) Set(value string) error {
 	if !f.hasBeenSet {
 		f.slice = []float64{}
@@ -43,12 +44,20 @@ func (f *Float64Slice) Set(value string) error {
 		return nil
 	}
 
-	tmp, err := strconv.ParseFloat(value, 64)
-	if err != nil {
-		return err
+	parts := strings.Split(value, ",")
+	values := make([]float64, 0, len(parts))
+	for i, part := range parts {
+		tmp, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			if numErr, ok := err.(*strconv.NumError); ok {
+				err = numErr.Err
+			}
+			return fmt.Errorf("element %d (%q) is not a float64: %s", i, part, err)
+		}
+		values = append(values, tmp)
 	}
 
-	f.slice = append(f.slice, tmp)
+	f.slice = append(f.slice, values...)
 	return nil
 }
 
@@ -75,16 +84,20 @@ func (f *Float64Slice) Get() interface{} {
 
 // Float64SliceFlag is a flag with type *Float64Slice
 type Float64SliceFlag struct {
-	Name        string
-	Aliases     []string
-	Usage       string
-	EnvVars     []string
-	FilePath    string
-	Required    bool
-	Hidden      bool
-	Value       *Float64Slice
-	DefaultText string
-	HasBeenSet  bool
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	Value        *Float64Slice
+	DefaultText  string
+	HasBeenSet   bool
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -108,6 +121,11 @@ func (f *Float64SliceFlag) IsRequired() bool {
 	return f.Required
 }
 
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *Float64SliceFlag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
 // TakesValue returns true if the flag takes a value, otherwise false
 func (f *Float64SliceFlag) TakesValue() bool {
 	return true
@@ -132,15 +150,34 @@ func (f *Float64SliceFlag) IsVisible() bool {
 	return !f.Hidden
 }
 
+// GetDeprecated returns the deprecation notice of the flag
+func (f *Float64SliceFlag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *Float64SliceFlag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *Float64SliceFlag) IsSensitive() bool {
+	return f.Sensitive
+}
+
 // Apply populates the flag given the flag set and environment
 func (f *Float64SliceFlag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
 		if val != "" {
 			f.Value = &Float64Slice{}
 
-			for _, s := range strings.Split(val, ",") {
-				if err := f.Value.Set(strings.TrimSpace(s)); err != nil {
-					return fmt.Errorf("could not parse %q as float64 slice value for flag %s: %s", f.Value, f.Name, err)
+			if parts := splitEnvSlice(val); len(parts) > 0 {
+				if err := f.Value.Set(strings.Join(parts, ",")); err != nil {
+					return fmt.Errorf("could not parse %q as float64 slice value for flag %s: %s", val, f.Name, err)
 				}
 			}
 
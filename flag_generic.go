@@ -3,6 +3,7 @@ package cli
 import (
 	"flag"
 	"fmt"
+	"strings"
 )
 
 // Generic is a generic parseable type identified by a specific flag
@@ -11,19 +12,65 @@ type Generic interface {
 	String() string
 }
 
+// EnumSetValue is a Generic holding a set of members chosen from Allowed,
+// given comma separated or by repeating the flag, e.g. "--features a,b".
+// Unknown members are rejected and repeated members are kept once.
+type EnumSetValue struct {
+	Allowed []string
+
+	values []string
+}
+
+// Set adds the comma separated members in value to the set
+func (e *EnumSetValue) Set(value string) error {
+	for _, member := range strings.Split(value, ",") {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			continue
+		}
+		if !containsString(e.Allowed, member) {
+			return fmt.Errorf("invalid value %q, must be one of: %s", member, strings.Join(e.Allowed, ", "))
+		}
+		if !containsString(e.values, member) {
+			e.values = append(e.values, member)
+		}
+	}
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (e *EnumSetValue) String() string {
+	if e == nil {
+		return ""
+	}
+	return strings.Join(e.values, ",")
+}
+
+// Values returns the members of the set in the order they were first given
+func (e *EnumSetValue) Values() []string {
+	return append([]string{}, e.values...)
+}
+
 // GenericFlag is a flag with type Generic
 type GenericFlag struct {
-	Name        string
-	Aliases     []string
-	Usage       string
-	EnvVars     []string
-	FilePath    string
-	Required    bool
-	Hidden      bool
-	TakesFile   bool
-	Value       Generic
-	DefaultText string
-	HasBeenSet  bool
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	TakesFile    bool
+	Value        Generic
+	DefaultText  string
+	HasBeenSet   bool
+	Destination  Generic
+
+	CompletionFunc FlagCompleteFunc
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -47,6 +94,11 @@ func (f *GenericFlag) IsRequired() bool {
 	return f.Required
 }
 
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *GenericFlag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
 // TakesValue returns true of the flag takes a value, otherwise false
 func (f *GenericFlag) TakesValue() bool {
 	return true
@@ -71,13 +123,49 @@ func (f *GenericFlag) IsVisible() bool {
 	return !f.Hidden
 }
 
+// GetDeprecated returns the deprecation notice of the flag
+func (f *GenericFlag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *GenericFlag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *GenericFlag) IsSensitive() bool {
+	return f.Sensitive
+}
+
+// GetCompletionFunc returns the function used to complete the flag's value
+// during shell completion, or nil if there is none
+func (f *GenericFlag) GetCompletionFunc() FlagCompleteFunc {
+	return f.CompletionFunc
+}
+
 // Apply takes the flagset and calls Set on the generic flag with the value
-// provided by the user for parsing by the flag
-func (f GenericFlag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
-		if val != "" {
-			if err := f.Value.Set(val); err != nil {
-				return fmt.Errorf("could not parse %q as value for flag %s: %s", val, f.Name, err)
+// provided by the user for parsing by the flag. When Destination is set it
+// receives the default from Value and is bound in its place.
+func (f *GenericFlag) Apply(set *flag.FlagSet) error {
+	value := f.Value
+	if f.Destination != nil {
+		if f.Value != nil && f.Value.String() != "" {
+			if err := f.Destination.Set(f.Value.String()); err != nil {
+				return fmt.Errorf("could not parse %q as default value for flag %s: %s", f.Value.String(), f.Name, err)
+			}
+		}
+		value = f.Destination
+	}
+
+	val, source, ok, err := flagFromEnvOrFileWithSource(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		if val != "" && value != nil {
+			if err := value.Set(val); err != nil {
+				return fmt.Errorf("could not parse %q from %s as value for flag %s: %s", val, source, f.Name, err)
 			}
 
 			f.HasBeenSet = true
@@ -85,7 +173,7 @@ func (f GenericFlag) Apply(set *flag.FlagSet) error {
 	}
 
 	for _, name := range f.Names() {
-		set.Var(f.Value, name, f.Usage)
+		set.Var(value, name, f.Usage)
 	}
 
 	return nil
@@ -8,17 +8,21 @@ import (
 
 // IntFlag is a flag with type int
 type IntFlag struct {
-	Name        string
-	Aliases     []string
-	Usage       string
-	EnvVars     []string
-	FilePath    string
-	Required    bool
-	Hidden      bool
-	Value       int
-	DefaultText string
-	Destination *int
-	HasBeenSet  bool
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	Value        int
+	DefaultText  string
+	Destination  *int
+	HasBeenSet   bool
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -42,6 +46,11 @@ func (f *IntFlag) IsRequired() bool {
 	return f.Required
 }
 
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *IntFlag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
 // TakesValue returns true of the flag takes a value, otherwise false
 func (f *IntFlag) TakesValue() bool {
 	return true
@@ -63,9 +72,28 @@ func (f *IntFlag) IsVisible() bool {
 	return !f.Hidden
 }
 
+// GetDeprecated returns the deprecation notice of the flag
+func (f *IntFlag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *IntFlag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *IntFlag) IsSensitive() bool {
+	return f.Sensitive
+}
+
 // Apply populates the flag given the flag set and environment
 func (f *IntFlag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
 		if val != "" {
 			valInt, err := strconv.ParseInt(val, 0, 64)
 
@@ -98,6 +126,24 @@ func (c *Context) Int(name string) int {
 	return 0
 }
 
+// IntOr looks up the value of a IntFlag, returns fallback
+// if it was not set on the command line, from the environment or from a file
+func (c *Context) IntOr(name string, fallback int) int {
+	if !c.IsSet(name) {
+		return fallback
+	}
+	return c.Int(name)
+}
+
+// TryInt looks up the value of a IntFlag, also reporting whether a flag
+// named name was found, unlike Int which returns 0 for unknown names
+func (c *Context) TryInt(name string) (int, bool) {
+	if fs := c.lookupFlagSet(name); fs != nil {
+		return lookupInt(name, fs), true
+	}
+	return 0, false
+}
+
 func lookupInt(name string, set *flag.FlagSet) int {
 	f := set.Lookup(name)
 	if f != nil {
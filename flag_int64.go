@@ -8,17 +8,21 @@ import (
 
 // Int64Flag is a flag with type int64
 type Int64Flag struct {
-	Name        string
-	Aliases     []string
-	Usage       string
-	EnvVars     []string
-	FilePath    string
-	Required    bool
-	Hidden      bool
-	Value       int64
-	DefaultText string
-	Destination *int64
-	HasBeenSet  bool
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	Value        int64
+	DefaultText  string
+	Destination  *int64
+	HasBeenSet   bool
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -42,6 +46,11 @@ func (f *Int64Flag) IsRequired() bool {
 	return f.Required
 }
 
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *Int64Flag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
 // TakesValue returns true of the flag takes a value, otherwise false
 func (f *Int64Flag) TakesValue() bool {
 	return true
@@ -63,9 +72,28 @@ func (f *Int64Flag) IsVisible() bool {
 	return !f.Hidden
 }
 
+// GetDeprecated returns the deprecation notice of the flag
+func (f *Int64Flag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *Int64Flag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *Int64Flag) IsSensitive() bool {
+	return f.Sensitive
+}
+
 // Apply populates the flag given the flag set and environment
 func (f *Int64Flag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
 		if val != "" {
 			valInt, err := strconv.ParseInt(val, 0, 64)
 
@@ -97,6 +125,24 @@ func (c *Context) Int64(name string) int64 {
 	return 0
 }
 
+// Int64Or looks up the value of a Int64Flag, returns fallback
+// if it was not set on the command line, from the environment or from a file
+func (c *Context) Int64Or(name string, fallback int64) int64 {
+	if !c.IsSet(name) {
+		return fallback
+	}
+	return c.Int64(name)
+}
+
+// TryInt64 looks up the value of a Int64Flag, also reporting whether a flag
+// named name was found, unlike Int64 which returns 0 for unknown names
+func (c *Context) TryInt64(name string) (int64, bool) {
+	if fs := c.lookupFlagSet(name); fs != nil {
+		return lookupInt64(name, fs), true
+	}
+	return 0, false
+}
+
 func lookupInt64(name string, set *flag.FlagSet) int64 {
 	f := set.Lookup(name)
 	if f != nil {
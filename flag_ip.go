@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ipValue wraps a net.IP to satisfy flag.Value
+type ipValue net.IP
+
+func newIPValue(val net.IP, p *net.IP) *ipValue {
+	*p = val
+	return (*ipValue)(p)
+}
+
+// Set parses the value as an IPv4 or IPv6 address
+func (ip *ipValue) Set(value string) error {
+	parsed := net.ParseIP(strings.TrimSpace(value))
+	if parsed == nil {
+		return fmt.Errorf("invalid IP address %q", value)
+	}
+	*ip = ipValue(parsed)
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (ip *ipValue) String() string {
+	if ip == nil || len(*ip) == 0 {
+		return ""
+	}
+	return net.IP(*ip).String()
+}
+
+// Get returns the net.IP set by this flag
+func (ip *ipValue) Get() interface{} {
+	return net.IP(*ip)
+}
+
+// IPFlag is a flag with type net.IP
+type IPFlag struct {
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	Value        net.IP
+	DefaultText  string
+	Destination  *net.IP
+	HasBeenSet   bool
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *IPFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *IPFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *IPFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *IPFlag) IsRequired() bool {
+	return f.Required
+}
+
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *IPFlag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *IPFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *IPFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *IPFlag) GetValue() string {
+	if f.Value != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// IsVisible returns true if the flag is not hidden, otherwise false
+func (f *IPFlag) IsVisible() bool {
+	return !f.Hidden
+}
+
+// GetDeprecated returns the deprecation notice of the flag
+func (f *IPFlag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *IPFlag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *IPFlag) IsSensitive() bool {
+	return f.Sensitive
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *IPFlag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		if val != "" {
+			var ip net.IP
+			if err := newIPValue(nil, &ip).Set(val); err != nil {
+				return fmt.Errorf("could not parse %q as IP value for flag %s: %s", val, f.Name, err)
+			}
+
+			f.Value = ip
+			f.HasBeenSet = true
+		}
+	}
+
+	for _, name := range f.Names() {
+		if f.Destination != nil {
+			set.Var(newIPValue(f.Value, f.Destination), name, f.Usage)
+			continue
+		}
+		set.Var(newIPValue(f.Value, new(net.IP)), name, f.Usage)
+	}
+
+	return nil
+}
+
+// IP looks up the value of a local IPFlag, returns
+// nil if not found
+func (c *Context) IP(name string) net.IP {
+	if fs := c.lookupFlagSet(name); fs != nil {
+		return lookupIP(name, fs)
+	}
+	return nil
+}
+
+func lookupIP(name string, set *flag.FlagSet) net.IP {
+	f := set.Lookup(name)
+	if f != nil {
+		if ip, ok := f.Value.(*ipValue); ok {
+			return net.IP(*ip)
+		}
+	}
+	return nil
+}
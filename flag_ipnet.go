@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ipNetValue wraps a net.IPNet to satisfy flag.Value
+type ipNetValue net.IPNet
+
+func newIPNetValue(val *net.IPNet, p *net.IPNet) *ipNetValue {
+	if val != nil {
+		*p = *val
+	}
+	return (*ipNetValue)(p)
+}
+
+// Set parses the value as an IP network in CIDR notation
+func (n *ipNetValue) Set(value string) error {
+	_, parsed, err := net.ParseCIDR(strings.TrimSpace(value))
+	if err != nil {
+		return fmt.Errorf("invalid CIDR network %q", value)
+	}
+	*n = ipNetValue(*parsed)
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (n *ipNetValue) String() string {
+	if n == nil || n.IP == nil {
+		return ""
+	}
+	ipNet := net.IPNet(*n)
+	return ipNet.String()
+}
+
+// Get returns the *net.IPNet set by this flag
+func (n *ipNetValue) Get() interface{} {
+	return n.value()
+}
+
+func (n *ipNetValue) value() *net.IPNet {
+	if n.IP == nil {
+		return nil
+	}
+	ipNet := net.IPNet(*n)
+	return &ipNet
+}
+
+// IPNetFlag is a flag with type *net.IPNet
+type IPNetFlag struct {
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	Value        *net.IPNet
+	DefaultText  string
+	Destination  *net.IPNet
+	HasBeenSet   bool
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *IPNetFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *IPNetFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *IPNetFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *IPNetFlag) IsRequired() bool {
+	return f.Required
+}
+
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *IPNetFlag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *IPNetFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *IPNetFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *IPNetFlag) GetValue() string {
+	if f.Value != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// IsVisible returns true if the flag is not hidden, otherwise false
+func (f *IPNetFlag) IsVisible() bool {
+	return !f.Hidden
+}
+
+// GetDeprecated returns the deprecation notice of the flag
+func (f *IPNetFlag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *IPNetFlag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *IPNetFlag) IsSensitive() bool {
+	return f.Sensitive
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *IPNetFlag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		if val != "" {
+			parsed := &ipNetValue{}
+			if err := parsed.Set(val); err != nil {
+				return fmt.Errorf("could not parse %q as IP network value for flag %s: %s", val, f.Name, err)
+			}
+
+			f.Value = parsed.value()
+			f.HasBeenSet = true
+		}
+	}
+
+	for _, name := range f.Names() {
+		if f.Destination != nil {
+			set.Var(newIPNetValue(f.Value, f.Destination), name, f.Usage)
+			continue
+		}
+		set.Var(newIPNetValue(f.Value, new(net.IPNet)), name, f.Usage)
+	}
+
+	return nil
+}
+
+// IPNet looks up the value of a local IPNetFlag, returns
+// nil if not found
+func (c *Context) IPNet(name string) *net.IPNet {
+	if fs := c.lookupFlagSet(name); fs != nil {
+		return lookupIPNet(name, fs)
+	}
+	return nil
+}
+
+func lookupIPNet(name string, set *flag.FlagSet) *net.IPNet {
+	f := set.Lookup(name)
+	if f != nil {
+		if n, ok := f.Value.(*ipNetValue); ok {
+			return n.value()
+		}
+	}
+	return nil
+}
@@ -1,20 +1,29 @@
 package cli
 
-import "flag"
+import (
+	"flag"
+	"fmt"
+)
 
 type PathFlag struct {
-	Name        string
-	Aliases     []string
-	Usage       string
-	EnvVars     []string
-	FilePath    string
-	Required    bool
-	Hidden      bool
-	TakesFile   bool
-	Value       string
-	DefaultText string
-	Destination *string
-	HasBeenSet  bool
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	TakesFile    bool
+	Value        string
+	DefaultText  string
+	Destination  *string
+	HasBeenSet   bool
+
+	CompletionFunc FlagCompleteFunc
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -38,6 +47,11 @@ func (f *PathFlag) IsRequired() bool {
 	return f.Required
 }
 
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *PathFlag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
 // TakesValue returns true of the flag takes a value, otherwise false
 func (f *PathFlag) TakesValue() bool {
 	return true
@@ -59,9 +73,34 @@ func (f *PathFlag) IsVisible() bool {
 	return !f.Hidden
 }
 
+// GetDeprecated returns the deprecation notice of the flag
+func (f *PathFlag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *PathFlag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *PathFlag) IsSensitive() bool {
+	return f.Sensitive
+}
+
+// GetCompletionFunc returns the function used to complete the flag's value
+// during shell completion, or nil if there is none
+func (f *PathFlag) GetCompletionFunc() FlagCompleteFunc {
+	return f.CompletionFunc
+}
+
 // Apply populates the flag given the flag set and environment
 func (f *PathFlag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
 		f.Value = val
 		f.HasBeenSet = true
 	}
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+)
+
+// regexpValue wraps a *regexp.Regexp to satisfy flag.Value
+type regexpValue struct {
+	re **regexp.Regexp
+}
+
+func newRegexpValue(val *regexp.Regexp, p **regexp.Regexp) *regexpValue {
+	*p = val
+	return &regexpValue{re: p}
+}
+
+// Set compiles the value as a regular expression
+func (r *regexpValue) Set(value string) error {
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return err
+	}
+	*r.re = re
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (r *regexpValue) String() string {
+	if r == nil || r.re == nil || *r.re == nil {
+		return ""
+	}
+	return (*r.re).String()
+}
+
+// Get returns the *regexp.Regexp set by this flag
+func (r *regexpValue) Get() interface{} {
+	return *r.re
+}
+
+// RegexpFlag is a flag with type *regexp.Regexp, the value given to it is
+// compiled with regexp.Compile and an invalid pattern is a parse error
+type RegexpFlag struct {
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	Value        *regexp.Regexp
+	DefaultText  string
+	Destination  **regexp.Regexp
+	HasBeenSet   bool
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *RegexpFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *RegexpFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *RegexpFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *RegexpFlag) IsRequired() bool {
+	return f.Required
+}
+
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *RegexpFlag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *RegexpFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *RegexpFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *RegexpFlag) GetValue() string {
+	if f.Value != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// IsVisible returns true if the flag is not hidden, otherwise false
+func (f *RegexpFlag) IsVisible() bool {
+	return !f.Hidden
+}
+
+// GetDeprecated returns the deprecation notice of the flag
+func (f *RegexpFlag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *RegexpFlag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *RegexpFlag) IsSensitive() bool {
+	return f.Sensitive
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *RegexpFlag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		if val != "" {
+			re, err := regexp.Compile(val)
+			if err != nil {
+				return fmt.Errorf("could not parse %q as regexp value for flag %s: %s", val, f.Name, err)
+			}
+
+			f.Value = re
+			f.HasBeenSet = true
+		}
+	}
+
+	re := f.Destination
+	if re == nil {
+		re = new(*regexp.Regexp)
+	}
+	value := newRegexpValue(f.Value, re)
+	for _, name := range f.Names() {
+		set.Var(value, name, f.Usage)
+	}
+
+	return nil
+}
+
+// Regexp looks up the value of a local RegexpFlag, returns
+// nil if not found
+func (c *Context) Regexp(name string) *regexp.Regexp {
+	if fs := c.lookupFlagSet(name); fs != nil {
+		return lookupRegexp(name, fs)
+	}
+	return nil
+}
+
+func lookupRegexp(name string, set *flag.FlagSet) *regexp.Regexp {
+	f := set.Lookup(name)
+	if f != nil {
+		if r, ok := f.Value.(*regexpValue); ok {
+			return *r.re
+		}
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits maps the lower cased suffixes of sizes to their number of bytes
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"kb":  1000,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"t":   1000 * 1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"p":   1000 * 1000 * 1000 * 1000 * 1000,
+	"pb":  1000 * 1000 * 1000 * 1000 * 1000,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+}
+
+// ParseSize parses a human readable size, e.g. "512MB" or "2GiB", into a
+// number of bytes. SI suffixes (KB, MB, ...) are powers of 1000 and binary
+// suffixes (KiB, MiB, ...) are powers of 1024. Suffixes are case-insensitive
+// and a number without a suffix is a number of bytes.
+func ParseSize(value string) (int64, error) {
+	s := strings.TrimSpace(value)
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+		i++
+	}
+
+	number, suffix := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	if number == "" {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+
+	unit, ok := sizeUnits[suffix]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown suffix %q", value, s[i:])
+	}
+
+	if n, err := strconv.ParseInt(number, 10, 64); err == nil {
+		if n > math.MaxInt64/unit {
+			return 0, fmt.Errorf("invalid size %q: out of range", value)
+		}
+		return n * unit, nil
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	if f*float64(unit) >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q: out of range", value)
+	}
+	return int64(f * float64(unit)), nil
+}
+
+// sizeValue wraps an int64 number of bytes to satisfy flag.Value
+type sizeValue int64
+
+func newSizeValue(val int64, p *int64) *sizeValue {
+	*p = val
+	return (*sizeValue)(p)
+}
+
+// Set parses the value with ParseSize
+func (s *sizeValue) Set(value string) error {
+	parsed, err := ParseSize(value)
+	if err != nil {
+		return err
+	}
+	*s = sizeValue(parsed)
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (s *sizeValue) String() string {
+	if s == nil {
+		return "0"
+	}
+	return strconv.FormatInt(int64(*s), 10)
+}
+
+// Get returns the number of bytes set by this flag
+func (s *sizeValue) Get() interface{} {
+	return int64(*s)
+}
+
+// SizeFlag is a flag with type int64 holding a number of bytes, given as a
+// human readable size such as 512MB or 2GiB
+type SizeFlag struct {
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	Value        int64
+	DefaultText  string
+	Destination  *int64
+	HasBeenSet   bool
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *SizeFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *SizeFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *SizeFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *SizeFlag) IsRequired() bool {
+	return f.Required
+}
+
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *SizeFlag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *SizeFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *SizeFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *SizeFlag) GetValue() string {
+	return fmt.Sprintf("%d", f.Value)
+}
+
+// IsVisible returns true if the flag is not hidden, otherwise false
+func (f *SizeFlag) IsVisible() bool {
+	return !f.Hidden
+}
+
+// GetDeprecated returns the deprecation notice of the flag
+func (f *SizeFlag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *SizeFlag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *SizeFlag) IsSensitive() bool {
+	return f.Sensitive
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *SizeFlag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		if val != "" {
+			valSize, err := ParseSize(val)
+			if err != nil {
+				return fmt.Errorf("could not parse %q as size value for flag %s: %s", val, f.Name, err)
+			}
+
+			f.Value = valSize
+			f.HasBeenSet = true
+		}
+	}
+
+	for _, name := range f.Names() {
+		if f.Destination != nil {
+			set.Var(newSizeValue(f.Value, f.Destination), name, f.Usage)
+			continue
+		}
+		set.Var(newSizeValue(f.Value, new(int64)), name, f.Usage)
+	}
+
+	return nil
+}
+
+// Size looks up the number of bytes of a local SizeFlag, returns
+// 0 if not found
+func (c *Context) Size(name string) int64 {
+	if fs := c.lookupFlagSet(name); fs != nil {
+		return lookupSize(name, fs)
+	}
+	return 0
+}
+
+// SizeOr looks up the number of bytes of a SizeFlag, returns fallback
+// if it was not set on the command line, from the environment or from a file
+func (c *Context) SizeOr(name string, fallback int64) int64 {
+	if !c.IsSet(name) {
+		return fallback
+	}
+	return c.Size(name)
+}
+
+// TrySize looks up the number of bytes of a SizeFlag, also reporting whether a flag
+// named name was found, unlike Size which returns 0 for unknown names
+func (c *Context) TrySize(name string) (int64, bool) {
+	if fs := c.lookupFlagSet(name); fs != nil {
+		return lookupSize(name, fs), true
+	}
+	return 0, false
+}
+
+func lookupSize(name string, set *flag.FlagSet) int64 {
+	f := set.Lookup(name)
+	if f != nil {
+		if s, ok := f.Value.(*sizeValue); ok {
+			return int64(*s)
+		}
+	}
+	return 0
+}
@@ -1,21 +1,69 @@
 package cli
 
-import "flag"
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// expandEnvStringValue wraps a string to satisfy flag.Value, expanding
+// ${VAR} and $VAR in the values it is set to against the environment
+type expandEnvStringValue string
+
+func newExpandEnvStringValue(val string, p *string) *expandEnvStringValue {
+	*p = val
+	return (*expandEnvStringValue)(p)
+}
+
+// Set sets the value with its environment variables expanded
+func (s *expandEnvStringValue) Set(value string) error {
+	*s = expandEnvStringValue(os.ExpandEnv(value))
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (s *expandEnvStringValue) String() string {
+	if s == nil {
+		return ""
+	}
+	return string(*s)
+}
+
+// Get returns the string set by this flag
+func (s *expandEnvStringValue) Get() interface{} {
+	return string(*s)
+}
 
 // StringFlag is a flag with type string
 type StringFlag struct {
-	Name        string
-	Aliases     []string
-	Usage       string
-	EnvVars     []string
-	FilePath    string
-	Required    bool
-	Hidden      bool
-	TakesFile   bool
-	Value       string
-	DefaultText string
-	Destination *string
-	HasBeenSet  bool
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	TakesFile    bool
+	Value        string
+	DefaultText  string
+	Destination  *string
+	HasBeenSet   bool
+
+	CompletionFunc FlagCompleteFunc
+
+	// EnvOnly makes the flag settable only through EnvVars and FilePath,
+	// e.g. for secrets that should not end up in the shell history. Giving
+	// it on the command line is an unknown flag error.
+	EnvOnly bool
+
+	// ExpandEnv expands ${VAR} and $VAR in the values given for the flag
+	// against the environment, e.g. --path $HOME/data. Unset variables
+	// expand to the empty string.
+	ExpandEnv bool
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -39,6 +87,11 @@ func (f *StringFlag) IsRequired() bool {
 	return f.Required
 }
 
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *StringFlag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
 // TakesValue returns true of the flag takes a value, otherwise false
 func (f *StringFlag) TakesValue() bool {
 	return true
@@ -60,13 +113,60 @@ func (f *StringFlag) IsVisible() bool {
 	return !f.Hidden
 }
 
+// GetDeprecated returns the deprecation notice of the flag
+func (f *StringFlag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *StringFlag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *StringFlag) IsSensitive() bool {
+	return f.Sensitive
+}
+
+// GetCompletionFunc returns the function used to complete the flag's value
+// during shell completion, or nil if there is none
+func (f *StringFlag) GetCompletionFunc() FlagCompleteFunc {
+	return f.CompletionFunc
+}
+
 // Apply populates the flag given the flag set and environment
 func (f *StringFlag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		if f.ExpandEnv {
+			val = os.ExpandEnv(val)
+		}
 		f.Value = val
 		f.HasBeenSet = true
 	}
 
+	if f.EnvOnly {
+		if f.Destination != nil {
+			*f.Destination = f.Value
+		}
+		return nil
+	}
+
+	if f.ExpandEnv {
+		dest := f.Destination
+		if dest == nil {
+			dest = new(string)
+		}
+		value := newExpandEnvStringValue(f.Value, dest)
+		for _, name := range f.Names() {
+			set.Var(value, name, f.Usage)
+		}
+		return nil
+	}
+
 	for _, name := range f.Names() {
 		if f.Destination != nil {
 			set.StringVar(f.Destination, name, f.Value, f.Usage)
@@ -84,9 +184,33 @@ func (c *Context) String(name string) string {
 	if fs := c.lookupFlagSet(name); fs != nil {
 		return lookupString(name, fs)
 	}
+	if f, ok := c.lookupFlag(name).(*StringFlag); ok && f.EnvOnly {
+		return f.Value
+	}
 	return ""
 }
 
+// StringOr looks up the value of a StringFlag, returns fallback
+// if it was not set on the command line, from the environment or from a file
+func (c *Context) StringOr(name string, fallback string) string {
+	if !c.IsSet(name) {
+		return fallback
+	}
+	return c.String(name)
+}
+
+// TryString looks up the value of a StringFlag, also reporting whether a flag
+// named name was found, unlike String which returns "" for unknown names
+func (c *Context) TryString(name string) (string, bool) {
+	if fs := c.lookupFlagSet(name); fs != nil {
+		return lookupString(name, fs), true
+	}
+	if f, ok := c.lookupFlag(name).(*StringFlag); ok && f.EnvOnly {
+		return f.Value, true
+	}
+	return "", false
+}
+
 func lookupString(name string, set *flag.FlagSet) string {
 	f := set.Lookup(name)
 	if f != nil {
@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// StringMap wraps a map[string]string to satisfy flag.Value
+type StringMap struct {
+	m          map[string]string
+	hasBeenSet bool
+}
+
+// NewStringMap creates a *StringMap with default values
+func NewStringMap(defaults map[string]string) *StringMap {
+	m := make(map[string]string, len(defaults))
+	for k, v := range defaults {
+		m[k] = v
+	}
+	return &StringMap{m: m}
+}
+
+// clone allocate a copy of self object
+func (s *StringMap) clone() *StringMap {
+	n := NewStringMap(s.m)
+	n.hasBeenSet = s.hasBeenSet
+	return n
+}
+
+// Set parses a key=value pair and adds it to the map of values, overwriting
+// any previous value of the same key
+func (s *StringMap) Set(value string) error {
+	if !s.hasBeenSet {
+		s.m = map[string]string{}
+		s.hasBeenSet = true
+	}
+
+	if strings.HasPrefix(value, slPfx) {
+		// Deserializing assumes overwrite
+		s.m = map[string]string{}
+		_ = json.Unmarshal([]byte(strings.Replace(value, slPfx, "", 1)), &s.m)
+		s.hasBeenSet = true
+		return nil
+	}
+
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("%q is not a key=value pair", value)
+	}
+
+	s.m[parts[0]] = parts[1]
+
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (s *StringMap) String() string {
+	return strings.Join(s.pairs(), ",")
+}
+
+// Serialize allows StringMap to fulfill Serializer
+func (s *StringMap) Serialize() string {
+	jsonBytes, _ := json.Marshal(s.m)
+	return fmt.Sprintf("%s%s", slPfx, string(jsonBytes))
+}
+
+// Value returns the map of strings set by this flag
+func (s *StringMap) Value() map[string]string {
+	return s.m
+}
+
+// Get returns the map of strings set by this flag
+func (s *StringMap) Get() interface{} {
+	return *s
+}
+
+// pairs returns the key=value pairs of the map sorted by key
+func (s *StringMap) pairs() []string {
+	pairs := make([]string, 0, len(s.m))
+	for k, v := range s.m {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// StringMapFlag is a flag with type *StringMap
+type StringMapFlag struct {
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	Value        *StringMap
+	DefaultText  string
+	HasBeenSet   bool
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *StringMapFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *StringMapFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *StringMapFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *StringMapFlag) IsRequired() bool {
+	return f.Required
+}
+
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *StringMapFlag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *StringMapFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *StringMapFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *StringMapFlag) GetValue() string {
+	if f.Value != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// IsVisible returns true if the flag is not hidden, otherwise false
+func (f *StringMapFlag) IsVisible() bool {
+	return !f.Hidden
+}
+
+// GetDeprecated returns the deprecation notice of the flag
+func (f *StringMapFlag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *StringMapFlag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *StringMapFlag) IsSensitive() bool {
+	return f.Sensitive
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *StringMapFlag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		if val != "" {
+			f.Value = &StringMap{}
+
+			for _, s := range splitEnvSlice(val) {
+				if err := f.Value.Set(s); err != nil {
+					return fmt.Errorf("could not parse %q as string map value for flag %s: %s", val, f.Name, err)
+				}
+			}
+
+			// Set this to false so that we reset the map if we then set values from
+			// flags that have already been set by the environment.
+			f.Value.hasBeenSet = false
+			f.HasBeenSet = true
+		}
+	}
+
+	if f.Value == nil {
+		f.Value = &StringMap{m: map[string]string{}}
+	}
+	copyValue := f.Value.clone()
+	for _, name := range f.Names() {
+		set.Var(copyValue, name, f.Usage)
+	}
+
+	return nil
+}
+
+// StringMap looks up the value of a local StringMapFlag, returns
+// nil if not found
+func (c *Context) StringMap(name string) map[string]string {
+	if fs := c.lookupFlagSet(name); fs != nil {
+		return lookupStringMap(name, fs)
+	}
+	return nil
+}
+
+func lookupStringMap(name string, set *flag.FlagSet) map[string]string {
+	f := set.Lookup(name)
+	if f != nil {
+		if m, ok := f.Value.(*StringMap); ok {
+			return m.Value()
+		}
+	}
+	return nil
+}
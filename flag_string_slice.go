@@ -11,6 +11,13 @@ import (
 type StringSlice struct {
 	slice      []string
 	hasBeenSet bool
+	// disableCommaSplit keeps commas in values, it is set from
+	// StringSliceFlag.DisableCommaSplit
+	disableCommaSplit bool
+
+	// field is a []string kept equal to slice, e.g. a struct field of
+	// FlagsFromStruct
+	field *[]string
 }
 
 // NewStringSlice creates a *StringSlice with default values
@@ -28,25 +35,48 @@ func (s *StringSlice) clone() *StringSlice {
 	return n
 }
 
-// Set appends the string value to the list of values
+// Set appends the string value to the list of values in the order they are
+// given, split on commas unless the StringSliceFlag has DisableCommaSplit,
+// so that "--tag a,b --tag c" gives [a b c]. The first call replaces the default
+// values, and values set from the environment or a file are replaced by
+// those given on the command line rather than merged with them.
 func (s *StringSlice) Set(value string) error {
-	if !s.hasBeenSet {
-		s.slice = []string{}
-		s.hasBeenSet = true
-	}
-
 	if strings.HasPrefix(value, slPfx) {
 		// Deserializing assumes overwrite
 		_ = json.Unmarshal([]byte(strings.Replace(value, slPfx, "", 1)), &s.slice)
 		s.hasBeenSet = true
+		s.sync()
 		return nil
 	}
 
-	s.slice = append(s.slice, value)
+	if s.disableCommaSplit {
+		s.add(value)
+	} else {
+		s.add(strings.Split(value, ",")...)
+	}
 
 	return nil
 }
 
+// add appends values to the list of values as they are, replacing the
+// defaults on the first call
+func (s *StringSlice) add(values ...string) {
+	if !s.hasBeenSet {
+		s.slice = []string{}
+		s.hasBeenSet = true
+	}
+
+	s.slice = append(s.slice, values...)
+	s.sync()
+}
+
+// sync copies the values to the field of the slice, if it has one
+func (s *StringSlice) sync() {
+	if s.field != nil {
+		*s.field = s.slice
+	}
+}
+
 // String returns a readable representation of this value (for usage defaults)
 func (s *StringSlice) String() string {
 	return fmt.Sprintf("%s", s.slice)
@@ -70,18 +100,36 @@ func (s *StringSlice) Get() interface{} {
 
 // StringSliceFlag is a flag with type *StringSlice
 type StringSliceFlag struct {
-	Name        string
-	Aliases     []string
-	Usage       string
-	EnvVars     []string
-	FilePath    string
-	Required    bool
-	Hidden      bool
-	TakesFile   bool
-	Value       *StringSlice
-	DefaultText string
-	HasBeenSet  bool
-	Destination *StringSlice
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	TakesFile    bool
+	Value        *StringSlice
+	DefaultText  string
+	HasBeenSet   bool
+
+	CompletionFunc FlagCompleteFunc
+	Destination    *StringSlice
+
+	// ReplaceOnSet makes the first value given on the command line replace
+	// the values the Destination or Value already hold, e.g. values loaded
+	// from a config file with StringSlice.Set before the App is run, which
+	// are otherwise appended to. Defaults and values from the environment or
+	// a file are always replaced.
+	ReplaceOnSet bool
+
+	// DisableCommaSplit keeps the values given on the command line as they
+	// are, commas included, e.g. for --header "Accept: a, b". Values are
+	// otherwise comma separated lists, e.g. --tag a,b for --tag a --tag b.
+	DisableCommaSplit bool
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -105,6 +153,11 @@ func (f *StringSliceFlag) IsRequired() bool {
 	return f.Required
 }
 
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *StringSliceFlag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
 // TakesValue returns true of the flag takes a value, otherwise false
 func (f *StringSliceFlag) TakesValue() bool {
 	return true
@@ -129,16 +182,42 @@ func (f *StringSliceFlag) IsVisible() bool {
 	return !f.Hidden
 }
 
+// GetDeprecated returns the deprecation notice of the flag
+func (f *StringSliceFlag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *StringSliceFlag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *StringSliceFlag) IsSensitive() bool {
+	return f.Sensitive
+}
+
+// GetCompletionFunc returns the function used to complete the flag's value
+// during shell completion, or nil if there is none
+func (f *StringSliceFlag) GetCompletionFunc() FlagCompleteFunc {
+	return f.CompletionFunc
+}
+
 // Apply populates the flag given the flag set and environment
 func (f *StringSliceFlag) Apply(set *flag.FlagSet) error {
 
 	if f.Destination != nil && f.Value != nil {
 		f.Destination.slice = make([]string, len(f.Value.slice))
 		copy(f.Destination.slice, f.Value.slice)
+		f.Destination.sync()
 
 	}
 
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
 		if f.Value == nil {
 			f.Value = &StringSlice{}
 		}
@@ -147,11 +226,7 @@ func (f *StringSliceFlag) Apply(set *flag.FlagSet) error {
 			destination = f.Destination
 		}
 
-		for _, s := range strings.Split(val, ",") {
-			if err := destination.Set(strings.TrimSpace(s)); err != nil {
-				return fmt.Errorf("could not parse %q as string value for flag %s: %s", val, f.Name, err)
-			}
-		}
+		destination.add(splitEnvSlice(val)...)
 
 		// Set this to false so that we reset the slice if we then set values from
 		// flags that have already been set by the environment.
@@ -166,6 +241,10 @@ func (f *StringSliceFlag) Apply(set *flag.FlagSet) error {
 	if f.Destination == nil {
 		setValue = f.Value.clone()
 	}
+	if f.ReplaceOnSet {
+		setValue.hasBeenSet = false
+	}
+	setValue.disableCommaSplit = f.DisableCommaSplit
 	for _, name := range f.Names() {
 		set.Var(setValue, name, f.Usage)
 	}
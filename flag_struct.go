@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FlagsFromStruct returns a flag for each field of the struct v points to
+// that has a `cli` tag, with the field as the Destination of the flag. The
+// tag holds the name of the flag, which defaults to the field name in kebab
+// case, followed by comma separated options:
+//
+//	alias=NAME   adds an alias, may be repeated
+//	env=VAR      adds an environment variable, may be repeated
+//	required     makes the flag required
+//	hidden       hides the flag from help
+//	usage=TEXT   sets the usage, which takes the rest of the tag
+//
+// For example `cli:"workers,alias=w,env=APP_WORKERS,usage=number of workers"`.
+// Fields tagged `cli:"-"` are skipped. Fields of type bool, int, int64, uint,
+// uint64, float64, string, time.Duration and []string are supported, the
+// current value of a field is the default of its flag. A []string field gets
+// a StringSliceFlag. It errors if v is not a non-nil pointer
+// to a struct, or on a tagged field of any other type.
+func FlagsFromStruct(v interface{}) ([]Flag, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("FlagsFromStruct needs a non-nil pointer to a struct, got %T", v)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	var flags []Flag
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag, ok := field.Tag.Lookup("cli")
+		if !ok || tag == "-" {
+			continue
+		}
+		if field.PkgPath != "" {
+			return nil, fmt.Errorf("field %s of %s is tagged but not exported", field.Name, rt)
+		}
+
+		opts, err := parseFlagTag(field.Name, tag)
+		if err != nil {
+			return nil, fmt.Errorf("field %s of %s: %s", field.Name, rt, err)
+		}
+
+		f, err := flagForField(rv.Field(i), opts)
+		if err != nil {
+			return nil, fmt.Errorf("field %s of %s: %s", field.Name, rt, err)
+		}
+		flags = append(flags, f)
+	}
+
+	return flags, nil
+}
+
+// flagTagOptions are the options given in the `cli` tag of a struct field
+type flagTagOptions struct {
+	name     string
+	aliases  []string
+	envVars  []string
+	usage    string
+	required bool
+	hidden   bool
+}
+
+func parseFlagTag(fieldName, tag string) (flagTagOptions, error) {
+	opts := flagTagOptions{}
+
+	parts := strings.Split(tag, ",")
+	opts.name = strings.TrimSpace(parts[0])
+	if opts.name == "" {
+		opts.name = kebabCase(fieldName)
+	}
+
+	for i := 1; i < len(parts); i++ {
+		part := strings.TrimSpace(parts[i])
+		key, value := part, ""
+		if j := strings.Index(part, "="); j >= 0 {
+			key, value = part[:j], part[j+1:]
+		}
+
+		switch key {
+		case "alias":
+			opts.aliases = append(opts.aliases, value)
+		case "env":
+			opts.envVars = append(opts.envVars, value)
+		case "required":
+			opts.required = true
+		case "hidden":
+			opts.hidden = true
+		case "usage":
+			// the usage may contain commas, so it takes the rest of the tag
+			opts.usage = strings.TrimSpace(strings.Join(append([]string{value}, parts[i+1:]...), ","))
+			return opts, nil
+		default:
+			return opts, fmt.Errorf("unknown option %q in cli tag", key)
+		}
+	}
+
+	return opts, nil
+}
+
+func flagForField(field reflect.Value, opts flagTagOptions) (Flag, error) {
+	var f Flag
+	switch p := field.Addr().Interface().(type) {
+	case *bool:
+		f = &BoolFlag{Value: *p, Destination: p}
+	case *int:
+		f = &IntFlag{Value: *p, Destination: p}
+	case *int64:
+		f = &Int64Flag{Value: *p, Destination: p}
+	case *uint:
+		f = &UintFlag{Value: *p, Destination: p}
+	case *uint64:
+		f = &Uint64Flag{Value: *p, Destination: p}
+	case *float64:
+		f = &Float64Flag{Value: *p, Destination: p}
+	case *string:
+		f = &StringFlag{Value: *p, Destination: p}
+	case *time.Duration:
+		f = &DurationFlag{Value: *p, Destination: p}
+	case *[]string:
+		f = &StringSliceFlag{
+			Value:       NewStringSlice(*p...),
+			Destination: &StringSlice{field: p},
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	// all of the flag types above have these fields
+	fv := flagValue(f)
+	fv.FieldByName("Name").SetString(opts.name)
+	fv.FieldByName("Aliases").Set(reflect.ValueOf(opts.aliases))
+	fv.FieldByName("Usage").SetString(opts.usage)
+	fv.FieldByName("EnvVars").Set(reflect.ValueOf(opts.envVars))
+	fv.FieldByName("Required").SetBool(opts.required)
+	fv.FieldByName("Hidden").SetBool(opts.hidden)
+
+	return f, nil
+}
+
+// kebabCase turns a field name such as "MaxRetries" into "max-retries"
+func kebabCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if i > 0 && (unicode.IsLower(runes[i-1]) || nextLower && unicode.IsUpper(runes[i-1])) {
+				b.WriteRune('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
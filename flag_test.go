@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"runtime"
@@ -51,6 +54,41 @@ func TestBoolFlagApply_SetsAllNames(t *testing.T) {
 	expect(t, v, true)
 }
 
+type primaryReader struct {
+	set     *flag.FlagSet
+	primary string
+	seen    []string
+}
+
+func (r *primaryReader) Set(value string) error {
+	r.seen = append(r.seen, r.set.Lookup(r.primary).Value.String())
+	return nil
+}
+
+func (r *primaryReader) String() string {
+	return ""
+}
+
+func TestFlagSet_AliasesShareValue(t *testing.T) {
+	set, err := flagSet("test", []Flag{
+		&IntFlag{Name: "workers", Aliases: []string{"w"}, Value: 1},
+		&StringFlag{Name: "name", Aliases: []string{"n"}, Value: "default"},
+	})
+	expect(t, err, nil)
+
+	// the value set through the alias is seen through the primary name while
+	// parsing, before normalizeFlags synchronizes the names
+	reader := &primaryReader{set: set, primary: "workers"}
+	set.Var(reader, "check", "")
+
+	err = set.Parse([]string{"-w", "4", "--check", "x", "-n", "alias"})
+	expect(t, err, nil)
+	expect(t, reader.seen, []string{"4"})
+	expect(t, set.Lookup("workers").Value.String(), "4")
+	expect(t, set.Lookup("name").Value.String(), "alias")
+	expect(t, set.Lookup("n").Value.String(), "alias")
+}
+
 func TestFlagsFromEnv(t *testing.T) {
 	newSetFloat64Slice := func(defaults ...float64) Float64Slice {
 		s := NewFloat64Slice(defaults...)
@@ -103,7 +141,7 @@ func TestFlagsFromEnv(t *testing.T) {
 		{"foobar", 0, &IntFlag{Name: "seconds", EnvVars: []string{"SECONDS"}}, `could not parse "foobar" as int value for flag seconds: .*`},
 
 		{"1.0,2", newSetFloat64Slice(1, 2), &Float64SliceFlag{Name: "seconds", EnvVars: []string{"SECONDS"}}, ""},
-		{"foobar", newSetFloat64Slice(), &Float64SliceFlag{Name: "seconds", EnvVars: []string{"SECONDS"}}, `could not parse "\[\]float64{}" as float64 slice value for flag seconds: .*`},
+		{"foobar", newSetFloat64Slice(), &Float64SliceFlag{Name: "seconds", EnvVars: []string{"SECONDS"}}, `could not parse "foobar" as float64 slice value for flag seconds: element 0 \("foobar"\) is not a float64: .*`},
 
 		{"1,2", newSetIntSlice(1, 2), &IntSliceFlag{Name: "seconds", EnvVars: []string{"SECONDS"}}, ""},
 		{"1.2,2", newSetIntSlice(), &IntSliceFlag{Name: "seconds", EnvVars: []string{"SECONDS"}}, `could not parse "1.2,2" as int slice value for flag seconds: .*`},
@@ -127,6 +165,7 @@ func TestFlagsFromEnv(t *testing.T) {
 		{"foobar", 0, &Uint64Flag{Name: "seconds", EnvVars: []string{"SECONDS"}}, `could not parse "foobar" as uint64 value for flag seconds: .*`},
 
 		{"foo,bar", &Parser{"foo", "bar"}, &GenericFlag{Name: "names", Value: &Parser{}, EnvVars: []string{"NAMES"}}, ""},
+		{"foobar", &Parser{}, &GenericFlag{Name: "names", Value: &Parser{}, EnvVars: []string{"NAMES"}}, `could not parse "foobar" from NAMES as value for flag names: invalid format`},
 	}
 
 	for i, test := range flagTests {
@@ -199,6 +238,25 @@ func TestStringFlagDefaultText(t *testing.T) {
 	}
 }
 
+func TestFlagDefaultTextOverridesValue(t *testing.T) {
+	tests := []struct {
+		flag     Flag
+		expected string
+	}{
+		{&IntFlag{Name: "port", Value: 8080, DefaultText: "random"}, "--port value\t(default: random)"},
+		{&DurationFlag{Name: "wait", Value: time.Second, DefaultText: "forever"}, "--wait value\t(default: forever)"},
+		{&StringSliceFlag{Name: "tag", Value: NewStringSlice("a", "b"), DefaultText: "none"}, "--tag value\t(default: none)\t(accepts multiple inputs)"},
+		{&IntSliceFlag{Name: "id", Value: NewIntSlice(1, 2), DefaultText: "all"}, "--id value\t(default: all)\t(accepts multiple inputs)"},
+	}
+
+	for _, test := range tests {
+		output := test.flag.String()
+		if output != test.expected {
+			t.Errorf("%q does not match %q", output, test.expected)
+		}
+	}
+}
+
 func TestStringFlagWithEnvVarHelpOutput(t *testing.T) {
 	defer resetEnv(os.Environ())
 	os.Clearenv()
@@ -956,6 +1014,151 @@ func TestGenericFlagApply_SetsAllNames(t *testing.T) {
 	expect(t, err, nil)
 }
 
+func TestGenericFlagApply_FromEnvVar(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_ORBS", "eleventy,3")
+
+	fl := &GenericFlag{Name: "orbs", Value: &Parser{}, EnvVars: []string{"APP_ORBS"}}
+	set := flag.NewFlagSet("test", 0)
+	err := fl.Apply(set)
+
+	expect(t, err, nil)
+	expect(t, fl.IsSet(), true)
+	expect(t, fl.Value, &Parser{"eleventy", "3"})
+}
+
+func TestFlagDeprecatedHelpOutput(t *testing.T) {
+	fl := &StringFlag{Name: "old", Usage: "the old name", Deprecated: "use --new", EnvVars: []string{"APP_OLD"}}
+	expected := "--old value\tthe old name (deprecated: use --new)" + withEnvHint([]string{"APP_OLD"}, "")
+	expect(t, fl.String(), expected)
+}
+
+func TestGenericFlagApply_WithDestination(t *testing.T) {
+	dest := &Parser{}
+	fl := GenericFlag{Name: "orbs", Aliases: []string{"O"}, Destination: dest}
+	set := flag.NewFlagSet("test", 0)
+	_ = fl.Apply(set)
+
+	err := set.Parse([]string{"-O", "eleventy,3"})
+	expect(t, err, nil)
+	expect(t, dest, &Parser{"eleventy", "3"})
+}
+
+func TestGenericFlagApply_DestinationFromEnvVar(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_ORBS", "eleventy,3")
+
+	dest := &Parser{}
+	fl := &GenericFlag{Name: "orbs", Value: &Parser{}, EnvVars: []string{"APP_ORBS"}, Destination: dest}
+	set := flag.NewFlagSet("test", 0)
+	err := fl.Apply(set)
+
+	expect(t, err, nil)
+	expect(t, fl.IsSet(), true)
+	expect(t, dest, &Parser{"eleventy", "3"})
+}
+
+func TestEnumSetValue(t *testing.T) {
+	features := &EnumSetValue{Allowed: []string{"a", "b", "c"}}
+	fl := GenericFlag{Name: "features", Value: features}
+	set := flag.NewFlagSet("test", 0)
+	_ = fl.Apply(set)
+
+	err := set.Parse([]string{"--features", "b,a", "--features", "b, c,a"})
+	expect(t, err, nil)
+	expect(t, features.Values(), []string{"b", "a", "c"})
+	expect(t, features.String(), "b,a,c")
+
+	err = features.Set("a,d")
+	if err == nil || err.Error() != `invalid value "d", must be one of: a, b, c` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestEnumSetValueFromEnvVar(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_FEATURES", "c,c")
+
+	var values []string
+	err := (&App{
+		Flags: []Flag{
+			&GenericFlag{Name: "features", Value: &EnumSetValue{Allowed: []string{"a", "b", "c"}}, EnvVars: []string{"APP_FEATURES"}},
+		},
+		Action: func(ctx *Context) error {
+			values = ctx.Generic("features").(*EnumSetValue).Values()
+			return nil
+		},
+	}).Run([]string{"run"})
+
+	expect(t, err, nil)
+	expect(t, values, []string{"c"})
+}
+
+func TestNormalizeFlags(t *testing.T) {
+	flags := []Flag{
+		&StringFlag{Name: "output", Aliases: []string{"o"}},
+		&BoolFlag{Name: "verbose", Aliases: []string{"v"}},
+	}
+	set := flag.NewFlagSet("test", 0)
+	for _, f := range flags {
+		_ = f.Apply(set)
+	}
+	_ = set.Parse([]string{"-o", "out.txt", "--verbose"})
+
+	err := NormalizeFlags(flags, set)
+	expect(t, err, nil)
+	expect(t, set.Lookup("output").Value.String(), "out.txt")
+	expect(t, set.Lookup("v").Value.String(), "true")
+
+	set = flag.NewFlagSet("test", 0)
+	for _, f := range flags {
+		_ = f.Apply(set)
+	}
+	_ = set.Parse([]string{"-o", "a", "--output", "b"})
+	if err := NormalizeFlags(flags, set); err == nil {
+		t.Error("expected an error when two forms of the same flag are used")
+	}
+}
+
+func TestNormalizeFlags_SharedValue(t *testing.T) {
+	labels := &appendingValue{}
+	flags := []Flag{
+		&GenericFlag{Name: "label", Aliases: []string{"l"}, Value: labels},
+	}
+	set := flag.NewFlagSet("test", 0)
+	for _, f := range flags {
+		_ = f.Apply(set)
+	}
+	_ = set.Parse([]string{"-l", "a", "-l", "b"})
+
+	err := NormalizeFlags(flags, set)
+	expect(t, err, nil)
+	expect(t, labels.values, []string{"a", "b"})
+
+	visited := false
+	set.Visit(func(f *flag.Flag) {
+		visited = visited || f.Name == "label"
+	})
+	expect(t, visited, true)
+}
+
+// appendingValue is a flag.Value that appends every value it is set to
+type appendingValue struct {
+	values []string
+}
+
+func (v *appendingValue) Set(value string) error {
+	v.values = append(v.values, value)
+	return nil
+}
+
+func (v *appendingValue) String() string {
+	return strings.Join(v.values, ",")
+}
+
 func TestParseMultiString(t *testing.T) {
 	_ = (&App{
 		Flags: []Flag{
@@ -1086,6 +1289,47 @@ func TestParseMultiStringSliceWithDestination(t *testing.T) {
 	}).Run([]string{"run", "-s", "10", "-s", "20"})
 }
 
+func TestParseMultiStringSliceMixedForms(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_TAGS", "x,y")
+
+	newApp := func(tags *[]string) *App {
+		return &App{
+			Flags: []Flag{
+				&StringSliceFlag{Name: "tag", Aliases: []string{"t"}, Value: NewStringSlice("default"), EnvVars: []string{"APP_TAGS"}},
+			},
+			Action: func(ctx *Context) error {
+				*tags = ctx.StringSlice("tag")
+				return nil
+			},
+		}
+	}
+
+	var tags []string
+	err := newApp(&tags).Run([]string{"run", "--tag", "a,b", "--tag", "c", "--tag", "d,e"})
+	expect(t, err, nil)
+	expect(t, tags, []string{"a", "b", "c", "d", "e"})
+
+	err = newApp(&tags).Run([]string{"run"})
+	expect(t, err, nil)
+	expect(t, tags, []string{"x", "y"})
+
+	// values of flags with DisableCommaSplit keep their commas
+	var headers []string
+	err = (&App{
+		Flags: []Flag{
+			&StringSliceFlag{Name: "header", DisableCommaSplit: true},
+		},
+		Action: func(ctx *Context) error {
+			headers = ctx.StringSlice("header")
+			return nil
+		},
+	}).Run([]string{"run", "--header", "Accept: a, b", "--header", "X-Id: 1"})
+	expect(t, err, nil)
+	expect(t, headers, []string{"Accept: a, b", "X-Id: 1"})
+}
+
 func TestParseMultiStringSliceWithDestinationAndEnv(t *testing.T) {
 	defer resetEnv(os.Environ())
 	os.Clearenv()
@@ -1147,6 +1391,29 @@ func TestParseMultiStringSliceFromEnv(t *testing.T) {
 	}).Run([]string{"run"})
 }
 
+func TestParseMultiStringSliceFromEnvWithDelimiter(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_HOSTS", "a.example.com\nb.example.com\n\nc.example.com\n")
+
+	defer func(delimiter string) { EnvSliceDelimiter = delimiter }(EnvSliceDelimiter)
+	EnvSliceDelimiter = "\n"
+
+	var hosts []string
+	err := (&App{
+		Flags: []Flag{
+			&StringSliceFlag{Name: "hosts", EnvVars: []string{"APP_HOSTS"}},
+		},
+		Action: func(ctx *Context) error {
+			hosts = ctx.StringSlice("hosts")
+			return nil
+		},
+	}).Run([]string{"run"})
+
+	expect(t, err, nil)
+	expect(t, hosts, []string{"a.example.com", "b.example.com", "c.example.com"})
+}
+
 func TestParseMultiStringSliceFromEnvWithDefaults(t *testing.T) {
 	defer resetEnv(os.Environ())
 	os.Clearenv()
@@ -1249,6 +1516,67 @@ func TestParseMultiInt(t *testing.T) {
 	}).Run([]string{"run", "-s", "10"})
 }
 
+var intBaseTests = []struct {
+	input    string
+	expected int64
+}{
+	{"255", 255},
+	{"0xFF", 255},
+	{"0o755", 493},
+	{"0755", 493},
+	{"0b1010", 10},
+	{"-0x10", -16},
+}
+
+func TestParseIntBasePrefixes(t *testing.T) {
+	for _, test := range intBaseTests {
+		var intVal int
+		var int64Val int64
+		err := (&App{
+			Flags: []Flag{
+				&IntFlag{Name: "mask"},
+				&Int64Flag{Name: "mask64"},
+			},
+			Action: func(ctx *Context) error {
+				intVal = ctx.Int("mask")
+				int64Val = ctx.Int64("mask64")
+				return nil
+			},
+		}).Run([]string{"run", "--mask", test.input, "--mask64", test.input})
+
+		expect(t, err, nil)
+		expect(t, intVal, int(test.expected))
+		expect(t, int64Val, test.expected)
+	}
+}
+
+func TestParseIntBasePrefixesFromEnv(t *testing.T) {
+	defer resetEnv(os.Environ())
+
+	for _, test := range intBaseTests {
+		os.Clearenv()
+		_ = os.Setenv("APP_MASK", test.input)
+
+		var intVal int
+		var int64Val int64
+		err := (&App{
+			Flags: []Flag{
+				&IntFlag{Name: "mask", EnvVars: []string{"APP_MASK"}},
+				&Int64Flag{Name: "mask64", EnvVars: []string{"APP_MASK"}},
+			},
+			Action: func(ctx *Context) error {
+				intVal = ctx.Int("mask")
+				int64Val = ctx.Int64("mask64")
+				return nil
+			},
+		}).Run([]string{"run"})
+
+		expect(t, err, nil)
+		expect(t, intVal, int(test.expected))
+		expect(t, int64Val, test.expected)
+	}
+}
+
 func TestParseDestinationInt(t *testing.T) {
 	var dest int
 	_ = (&App{
@@ -1555,6 +1883,43 @@ func TestParseMultiFloat64FromEnvCascade(t *testing.T) {
 	}).Run([]string{"run"})
 }
 
+func TestParseMultiFloat64Slice(t *testing.T) {
+	called := false
+	err := (&App{
+		Flags: []Flag{
+			&Float64SliceFlag{Name: "weights", Aliases: []string{"w"}},
+		},
+		Action: func(ctx *Context) error {
+			called = true
+			expected := []float64{0.1, 0.2, 0.3, 1.5}
+			if !reflect.DeepEqual(ctx.Float64Slice("weights"), expected) {
+				t.Errorf("main name not set: %v != %v", expected, ctx.Float64Slice("weights"))
+			}
+			if !reflect.DeepEqual(ctx.Float64Slice("w"), expected) {
+				t.Errorf("short name not set: %v != %v", expected, ctx.Float64Slice("w"))
+			}
+			return nil
+		},
+	}).Run([]string{"run", "--weights", "0.1,0.2,0.3", "--weights", "1.5"})
+	expect(t, err, nil)
+	expect(t, called, true)
+}
+
+func TestParseFloat64SliceInvalidElement(t *testing.T) {
+	fl := Float64SliceFlag{Name: "weights"}
+	set := flag.NewFlagSet("test", 0)
+	set.SetOutput(ioutil.Discard)
+	_ = fl.Apply(set)
+
+	err := set.Parse([]string{"--weights", "0.1,abc,0.3"})
+	if err == nil {
+		t.Fatal("expected an error for the invalid element")
+	}
+	if !strings.Contains(err.Error(), `element 1 ("abc") is not a float64: invalid syntax`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
 func TestParseMultiFloat64SliceFromEnv(t *testing.T) {
 	defer resetEnv(os.Environ())
 	os.Clearenv()
@@ -1597,6 +1962,90 @@ func TestParseMultiFloat64SliceFromEnvCascade(t *testing.T) {
 	}).Run([]string{"run"})
 }
 
+func TestStringMapFlagHelpOutput(t *testing.T) {
+	fl := &StringMapFlag{Name: "label", Aliases: []string{"l"}, Value: NewStringMap(map[string]string{"b": "2", "a": "1"})}
+	output := fl.String()
+
+	expected := "--label value, -l value\t(default: \"a=1\", \"b=2\")\t(accepts multiple inputs)"
+	if output != expected {
+		t.Errorf("%q does not match %q", output, expected)
+	}
+}
+
+func TestParseMultiStringMap(t *testing.T) {
+	called := false
+	err := (&App{
+		Flags: []Flag{
+			&StringMapFlag{Name: "label", Aliases: []string{"l"}},
+		},
+		Action: func(ctx *Context) error {
+			called = true
+			expected := map[string]string{"env": "prod", "team": "core"}
+			if !reflect.DeepEqual(ctx.StringMap("label"), expected) {
+				t.Errorf("main name not set: %v != %v", expected, ctx.StringMap("label"))
+			}
+			if !reflect.DeepEqual(ctx.StringMap("l"), expected) {
+				t.Errorf("short name not set: %v != %v", expected, ctx.StringMap("l"))
+			}
+			return nil
+		},
+	}).Run([]string{"run", "-l", "env=dev", "-l", "team=core", "-l", "env=prod"})
+	expect(t, err, nil)
+	expect(t, called, true)
+}
+
+func TestParseMultiStringMapWithDefaults(t *testing.T) {
+	_ = (&App{
+		Flags: []Flag{
+			&StringMapFlag{Name: "label", Value: NewStringMap(map[string]string{"env": "dev"})},
+		},
+		Action: func(ctx *Context) error {
+			expected := map[string]string{"team": "core"}
+			if !reflect.DeepEqual(ctx.StringMap("label"), expected) {
+				t.Errorf("defaults not overridden: %v != %v", expected, ctx.StringMap("label"))
+			}
+			return nil
+		},
+	}).Run([]string{"run", "--label", "team=core"})
+}
+
+func TestParseMultiStringMapFromEnv(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_LABELS", "env=dev, team=core")
+
+	_ = (&App{
+		Flags: []Flag{
+			&StringMapFlag{Name: "label", EnvVars: []string{"APP_LABELS"}},
+		},
+		Action: func(ctx *Context) error {
+			expected := map[string]string{"env": "dev", "team": "core"}
+			if !reflect.DeepEqual(ctx.StringMap("label"), expected) {
+				t.Errorf("not set from env: %v != %v", expected, ctx.StringMap("label"))
+			}
+			return nil
+		},
+	}).Run([]string{"run"})
+}
+
+func TestParseStringMapMissingSeparator(t *testing.T) {
+	app := &App{
+		Flags: []Flag{
+			&StringMapFlag{Name: "label"},
+		},
+		Writer: ioutil.Discard,
+		Action: func(ctx *Context) error {
+			t.Errorf("action should not be called")
+			return nil
+		},
+	}
+
+	err := app.Run([]string{"run", "--label", "env"})
+	if err == nil || !strings.Contains(err.Error(), `"env" is not a key=value pair`) {
+		t.Errorf("expected key=value error, got %v", err)
+	}
+}
+
 func TestParseMultiBool(t *testing.T) {
 	_ = (&App{
 		Flags: []Flag{
@@ -1656,6 +2105,88 @@ func TestParseDestinationBool(t *testing.T) {
 	}).Run([]string{"run", "--dest"})
 }
 
+func TestParseBoolWords(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    bool
+		wantErr bool
+	}{
+		{args: []string{"--debug"}, want: true},
+		{args: []string{"--debug=yes"}, want: true},
+		{args: []string{"--debug=On"}, want: true},
+		{args: []string{"--debug=OFF"}, want: false},
+		{args: []string{"--debug=no"}, want: false},
+		{args: []string{"--debug=0"}, want: false},
+		{args: []string{"--debug=nope"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		var debug bool
+		err := (&App{
+			Writer: ioutil.Discard,
+			Flags: []Flag{
+				&BoolFlag{Name: "debug", Value: true},
+			},
+			Action: func(ctx *Context) error {
+				debug = ctx.Bool("debug")
+				return nil
+			},
+		}).Run(append([]string{"run"}, test.args...))
+
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("expected an error for %v", test.args)
+			}
+			continue
+		}
+		expect(t, err, nil)
+		expect(t, debug, test.want)
+	}
+}
+
+func TestBoolFlagApply_InvalidWordFromEnv(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_DEBUG", "maybe")
+
+	fl := &BoolFlag{Name: "debug", EnvVars: []string{"APP_DEBUG"}}
+	err := fl.Apply(flag.NewFlagSet("test", 0))
+	if err == nil || !strings.HasPrefix(err.Error(), `could not parse "maybe" as bool value for flag debug`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestBoolFlagApply_EmptyEnvMeansTrue(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("MYFLAG", "")
+
+	fl := &BoolFlag{Name: "myflag", EnvVars: []string{"MYFLAG"}, EmptyEnvMeansTrue: true}
+	set := flag.NewFlagSet("test", 0)
+	expect(t, fl.Apply(set), nil)
+	expect(t, fl.IsSet(), true)
+	expect(t, lookupBool("myflag", set), true)
+
+	fl = &BoolFlag{Name: "myflag", EnvVars: []string{"MYFLAG"}}
+	set = flag.NewFlagSet("test", 0)
+	expect(t, fl.Apply(set), nil)
+	expect(t, fl.IsSet(), false)
+	expect(t, lookupBool("myflag", set), false)
+
+	_ = os.Setenv("MYFLAG", "false")
+	fl = &BoolFlag{Name: "myflag", EnvVars: []string{"MYFLAG"}, EmptyEnvMeansTrue: true}
+	set = flag.NewFlagSet("test", 0)
+	expect(t, fl.Apply(set), nil)
+	expect(t, lookupBool("myflag", set), false)
+
+	os.Clearenv()
+	fl = &BoolFlag{Name: "myflag", EnvVars: []string{"MYFLAG"}, EmptyEnvMeansTrue: true}
+	set = flag.NewFlagSet("test", 0)
+	expect(t, fl.Apply(set), nil)
+	expect(t, fl.IsSet(), false)
+	expect(t, lookupBool("myflag", set), false)
+}
+
 func TestParseMultiBoolFromEnv(t *testing.T) {
 	defer resetEnv(os.Environ())
 	os.Clearenv()
@@ -1705,6 +2236,8 @@ func TestParseBoolFromEnv(t *testing.T) {
 		{"1", true},
 		{"false", false},
 		{"true", true},
+		{"yes", true},
+		{"OFF", false},
 	}
 
 	for _, test := range boolFlagTests {
@@ -1860,13 +2393,51 @@ func TestFlagFromFile(t *testing.T) {
 	}
 
 	for _, filePathTest := range filePathTests {
-		got, _ := flagFromEnvOrFile(filePathTest.name, filePathTest.path)
+		got, _, _ := flagFromEnvOrFile(filePathTest.name, filePathTest.path)
 		if want := filePathTest.expected; got != want {
 			t.Errorf("Did not expect %v - Want %v", got, want)
 		}
 	}
 }
 
+func TestFlagFromFileCandidates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "urfave_cli_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	second := filepath.Join(dir, "second")
+	_ = ioutil.WriteFile(second, []byte("from-second"), 0644)
+	missing := filepath.Join(dir, "missing")
+
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+
+	var name string
+	err = (&App{
+		Flags: []Flag{
+			&StringFlag{Name: "name", FilePath: missing + "," + second},
+		},
+		Action: func(ctx *Context) error {
+			name = ctx.String("name")
+			return nil
+		},
+	}).Run([]string{"run"})
+	expect(t, err, nil)
+	expect(t, name, "from-second")
+
+	// a directory exists but cannot be read as a file
+	fl := &StringFlag{Name: "name", FilePath: dir + "," + second}
+	err = fl.Apply(flag.NewFlagSet("test", 0))
+	if err == nil {
+		t.Fatal("expected an error for an unreadable file")
+	}
+	if !strings.HasPrefix(err.Error(), "could not read file for flag name: ") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
 func TestStringSlice_Serialized_Set(t *testing.T) {
 	sl0 := NewStringSlice("a", "b")
 	ser0 := sl0.Serialize()
@@ -2059,3 +2630,587 @@ func TestTimestampFlagApply_WithDestination(t *testing.T) {
 	expect(t, err, nil)
 	expect(t, *fl.Destination.timestamp, expectedResult)
 }
+
+func TestIPFlagHelpOutput(t *testing.T) {
+	fl := &IPFlag{Name: "bind", Usage: "address to `ADDR` bind"}
+	expect(t, fl.String(), "--bind ADDR\taddress to ADDR bind")
+
+	fl = &IPFlag{Name: "bind", Value: net.ParseIP("127.0.0.1")}
+	expect(t, fl.String(), "--bind value\t(default: 127.0.0.1)")
+}
+
+func TestParseIP(t *testing.T) {
+	var ip net.IP
+	err := (&App{
+		Flags: []Flag{
+			&IPFlag{Name: "bind", Aliases: []string{"b"}},
+		},
+		Action: func(ctx *Context) error {
+			ip = ctx.IP("b")
+			return nil
+		},
+	}).Run([]string{"run", "--bind", "::1"})
+	expect(t, err, nil)
+	expect(t, ip.String(), "::1")
+}
+
+func TestParseIPInvalid(t *testing.T) {
+	fl := &IPFlag{Name: "bind"}
+	set := flag.NewFlagSet("test", 0)
+	set.SetOutput(ioutil.Discard)
+	_ = fl.Apply(set)
+
+	err := set.Parse([]string{"--bind", "300.1.1.1"})
+	if err == nil || !strings.Contains(err.Error(), `invalid IP address "300.1.1.1"`) {
+		t.Errorf("expected an invalid IP address error, got %v", err)
+	}
+}
+
+func TestParseIPFromEnv(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_BIND", "10.0.0.1")
+
+	var dest net.IP
+	fl := &IPFlag{Name: "bind", EnvVars: []string{"APP_BIND"}, Destination: &dest}
+	set := flag.NewFlagSet("test", 0)
+	expect(t, fl.Apply(set), nil)
+	expect(t, fl.IsSet(), true)
+	expect(t, dest.String(), "10.0.0.1")
+
+	_ = os.Setenv("APP_BIND", "nope")
+	fl = &IPFlag{Name: "bind", EnvVars: []string{"APP_BIND"}}
+	err := fl.Apply(flag.NewFlagSet("test", 0))
+	expect(t, err.Error(), `could not parse "nope" as IP value for flag bind: invalid IP address "nope"`)
+}
+
+func TestIPNetFlagHelpOutput(t *testing.T) {
+	_, network, _ := net.ParseCIDR("10.0.0.0/8")
+	fl := &IPNetFlag{Name: "allow", Value: network}
+	expect(t, fl.String(), "--allow value\t(default: 10.0.0.0/8)")
+
+	fl = &IPNetFlag{Name: "allow"}
+	expect(t, fl.String(), "--allow value\t")
+}
+
+func TestParseIPNet(t *testing.T) {
+	var network *net.IPNet
+	err := (&App{
+		Flags: []Flag{
+			&IPNetFlag{Name: "allow", Aliases: []string{"a"}},
+		},
+		Action: func(ctx *Context) error {
+			network = ctx.IPNet("allow")
+			return nil
+		},
+	}).Run([]string{"run", "-a", "192.168.1.17/24"})
+	expect(t, err, nil)
+	expect(t, network.String(), "192.168.1.0/24")
+}
+
+func TestParseIPNetFromEnv(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_ALLOW", "fd00::/8")
+
+	var network *net.IPNet
+	err := (&App{
+		Flags: []Flag{
+			&IPNetFlag{Name: "allow", EnvVars: []string{"APP_ALLOW"}},
+		},
+		Action: func(ctx *Context) error {
+			network = ctx.IPNet("allow")
+			return nil
+		},
+	}).Run([]string{"run"})
+	expect(t, err, nil)
+	expect(t, network.String(), "fd00::/8")
+
+	_ = os.Setenv("APP_ALLOW", "10.0.0.1")
+	fl := &IPNetFlag{Name: "allow", EnvVars: []string{"APP_ALLOW"}}
+	err = fl.Apply(flag.NewFlagSet("test", 0))
+	expect(t, err.Error(), `could not parse "10.0.0.1" as IP network value for flag allow: invalid CIDR network "10.0.0.1"`)
+}
+
+func TestIPNetUnset(t *testing.T) {
+	fl := &IPNetFlag{Name: "allow"}
+	set := flag.NewFlagSet("test", 0)
+	_ = fl.Apply(set)
+	_ = set.Parse(nil)
+
+	c := NewContext(nil, set, nil)
+	if c.IPNet("allow") != nil {
+		t.Errorf("expected a nil network, got %v", c.IPNet("allow"))
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected int64
+	}{
+		{"1KiB", 1024},
+		{"1KB", 1000},
+		{"512MB", 512000000},
+		{"2GiB", 2 << 30},
+		{"1.5kb", 1500},
+		{"10 mib", 10 << 20},
+		{"42", 42},
+		{"42B", 42},
+	} {
+		size, err := ParseSize(test.input)
+		expect(t, err, nil)
+		expect(t, size, test.expected)
+	}
+
+	for _, input := range []string{"10XB", "MB", "", "-1KB", "1.2.3KB", "9999999PiB"} {
+		if _, err := ParseSize(input); err == nil {
+			t.Errorf("expected an error for %q", input)
+		}
+	}
+}
+
+func TestParseSizeFlag(t *testing.T) {
+	var size, dest int64
+	err := (&App{
+		Flags: []Flag{
+			&SizeFlag{Name: "max-size", Aliases: []string{"m"}, Destination: &dest},
+		},
+		Action: func(ctx *Context) error {
+			size = ctx.Size("m")
+			return nil
+		},
+	}).Run([]string{"run", "--max-size", "1KiB"})
+	expect(t, err, nil)
+	expect(t, size, int64(1024))
+	expect(t, dest, int64(1024))
+
+	fl := &SizeFlag{Name: "max-size"}
+	set := flag.NewFlagSet("test", 0)
+	set.SetOutput(ioutil.Discard)
+	_ = fl.Apply(set)
+	err = set.Parse([]string{"--max-size", "10XB"})
+	if err == nil || !strings.Contains(err.Error(), `flag -max-size: invalid size "10XB": unknown suffix "XB"`) {
+		t.Errorf("expected an invalid size error, got %v", err)
+	}
+}
+
+func TestParseSizeFlagFromEnv(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_MAX_SIZE", "1KB")
+
+	fl := &SizeFlag{Name: "max-size", EnvVars: []string{"APP_MAX_SIZE"}}
+	expect(t, fl.Apply(flag.NewFlagSet("test", 0)), nil)
+	expect(t, fl.IsSet(), true)
+	expect(t, fl.Value, int64(1000))
+
+	_ = os.Setenv("APP_MAX_SIZE", "1KX")
+	fl = &SizeFlag{Name: "max-size", EnvVars: []string{"APP_MAX_SIZE"}}
+	err := fl.Apply(flag.NewFlagSet("test", 0))
+	expect(t, err.Error(), `could not parse "1KX" as size value for flag max-size: invalid size "1KX": unknown suffix "KX"`)
+}
+
+func TestURLFlagHelpOutput(t *testing.T) {
+	endpoint, _ := url.Parse("https://example.com/api")
+	fl := &URLFlag{Name: "endpoint", Usage: "the API `URL`", Value: endpoint}
+	expect(t, fl.String(), "--endpoint URL\tthe API URL (default: https://example.com/api)")
+}
+
+func TestParseURL(t *testing.T) {
+	var endpoint *url.URL
+	err := (&App{
+		Flags: []Flag{
+			&URLFlag{Name: "endpoint", Aliases: []string{"e"}},
+		},
+		Action: func(ctx *Context) error {
+			endpoint = ctx.URL("e")
+			return nil
+		},
+	}).Run([]string{"run", "--endpoint", "http://localhost:8080/v1"})
+	expect(t, err, nil)
+	expect(t, endpoint.Host, "localhost:8080")
+	expect(t, endpoint.Path, "/v1")
+}
+
+func TestParseURLInvalid(t *testing.T) {
+	cases := []struct {
+		flag     *URLFlag
+		input    string
+		expected string
+	}{
+		{&URLFlag{Name: "endpoint"}, "example.com", `URL "example.com" must have a scheme and a host`},
+		{&URLFlag{Name: "endpoint"}, "file:///tmp/x", `URL "file:///tmp/x" must have a scheme and a host`},
+		{&URLFlag{Name: "endpoint", AllowedSchemes: []string{"https"}}, "http://example.com", `URL "http://example.com" must have one of the schemes https`},
+	}
+
+	for _, c := range cases {
+		set := flag.NewFlagSet("test", 0)
+		set.SetOutput(ioutil.Discard)
+		_ = c.flag.Apply(set)
+
+		err := set.Parse([]string{"--endpoint", c.input})
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("expected error containing %q, got %v", c.expected, err)
+		}
+	}
+}
+
+func TestParseURLFromEnv(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_ENDPOINT", "HTTPS://example.com")
+
+	var dest url.URL
+	fl := &URLFlag{Name: "endpoint", EnvVars: []string{"APP_ENDPOINT"}, AllowedSchemes: []string{"https"}, Destination: &dest}
+	expect(t, fl.Apply(flag.NewFlagSet("test", 0)), nil)
+	expect(t, fl.IsSet(), true)
+	expect(t, dest.String(), "https://example.com")
+
+	_ = os.Setenv("APP_ENDPOINT", "ftp://example.com")
+	fl = &URLFlag{Name: "endpoint", EnvVars: []string{"APP_ENDPOINT"}, AllowedSchemes: []string{"https"}}
+	err := fl.Apply(flag.NewFlagSet("test", 0))
+	expect(t, err.Error(), `could not parse "ftp://example.com" as URL value for flag endpoint: URL "ftp://example.com" must have one of the schemes https`)
+}
+
+func TestRegexpFlagHelpOutput(t *testing.T) {
+	fl := &RegexpFlag{Name: "match", Usage: "only run tests matching `PATTERN`", Value: regexp.MustCompile("^Test")}
+	expect(t, fl.String(), "--match PATTERN\tonly run tests matching PATTERN (default: ^Test)")
+}
+
+func TestParseRegexp(t *testing.T) {
+	var dest *regexp.Regexp
+	var match *regexp.Regexp
+	err := (&App{
+		Flags: []Flag{
+			&RegexpFlag{Name: "match", Aliases: []string{"m"}, Destination: &dest},
+		},
+		Action: func(ctx *Context) error {
+			match = ctx.Regexp("m")
+			return nil
+		},
+	}).Run([]string{"run", "--match", "^api-[0-9]+$"})
+	expect(t, err, nil)
+	expect(t, match.MatchString("api-42"), true)
+	expect(t, match.MatchString("web-42"), false)
+	expect(t, dest, match)
+}
+
+func TestParseRegexpInvalid(t *testing.T) {
+	set := flag.NewFlagSet("test", 0)
+	set.SetOutput(ioutil.Discard)
+	_ = (&RegexpFlag{Name: "match"}).Apply(set)
+
+	err := set.Parse([]string{"--match", "a(b"})
+	if err == nil || !strings.Contains(err.Error(), "missing closing )") {
+		t.Errorf("expected a missing closing ) error, got %v", err)
+	}
+}
+
+func TestParseRegexpFromEnv(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_MATCH", "^v[0-9]")
+
+	fl := &RegexpFlag{Name: "match", EnvVars: []string{"APP_MATCH"}}
+	set := flag.NewFlagSet("test", 0)
+	expect(t, fl.Apply(set), nil)
+	expect(t, fl.IsSet(), true)
+	expect(t, lookupRegexp("match", set).String(), "^v[0-9]")
+
+	_ = os.Setenv("APP_MATCH", "[")
+	fl = &RegexpFlag{Name: "match", EnvVars: []string{"APP_MATCH"}}
+	err := fl.Apply(flag.NewFlagSet("test", 0))
+	expect(t, err.Error(), "could not parse \"[\" as regexp value for flag match: error parsing regexp: missing closing ]: `[`")
+}
+
+func TestStringFlagEnvOnly(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_TOKEN", "s3cr3t")
+
+	var token, destination string
+	var isSet bool
+	newApp := func() *App {
+		return &App{
+			Name:   "app",
+			Writer: ioutil.Discard,
+			Flags: []Flag{
+				&StringFlag{Name: "token", Usage: "access token", EnvVars: []string{"APP_TOKEN"}, EnvOnly: true, Destination: &destination},
+			},
+			Action: func(c *Context) error {
+				token = c.String("token")
+				isSet = c.IsSet("token")
+				return nil
+			},
+		}
+	}
+
+	err := newApp().Run([]string{"app"})
+	expect(t, err, nil)
+	expect(t, token, "s3cr3t")
+	expect(t, destination, "s3cr3t")
+	expect(t, isSet, true)
+
+	err = newApp().Run([]string{"app", "--token", "leaked"})
+	expect(t, err, &UnknownFlagError{Flag: "--token", Command: "app"})
+
+	fl := &StringFlag{Name: "token", Usage: "access token", EnvVars: []string{"APP_TOKEN"}, EnvOnly: true, Value: "s3cr3t"}
+	help := fl.String()
+	if !strings.Contains(help, "(environment or file only)") {
+		t.Errorf("expected env only note in %q", help)
+	}
+	if strings.Contains(help, "s3cr3t") {
+		t.Errorf("expected no value in %q", help)
+	}
+	if strings.Contains(help, "--token") {
+		t.Errorf("expected no flag name in %q", help)
+	}
+	if !strings.HasPrefix(help, envVarNames([]string{"APP_TOKEN"})+"\t") {
+		t.Errorf("expected the env var in place of the flag name in %q", help)
+	}
+}
+
+func TestParseCountFlag(t *testing.T) {
+	var tests = []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"run"}, 0},
+		{[]string{"run", "--verbose"}, 1},
+		{[]string{"run", "-v", "-v", "-v"}, 3},
+		{[]string{"run", "--verbose=3"}, 3},
+		{[]string{"run", "--verbose=3", "--verbose"}, 4},
+	}
+
+	for _, test := range tests {
+		var count, dest int
+		err := (&App{
+			Flags: []Flag{
+				&CountFlag{Name: "verbose", Aliases: []string{"v"}, Destination: &dest},
+			},
+			Action: func(ctx *Context) error {
+				count = ctx.Int("v")
+				return nil
+			},
+		}).Run(test.args)
+		expect(t, err, nil)
+		expect(t, count, test.expected)
+		expect(t, dest, test.expected)
+	}
+}
+
+func TestParseCountFlagFromEnv(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_VERBOSE", "2")
+
+	var count int
+	err := (&App{
+		Flags: []Flag{
+			&CountFlag{Name: "verbose", EnvVars: []string{"APP_VERBOSE"}},
+		},
+		Action: func(ctx *Context) error {
+			count = ctx.Int("verbose")
+			return nil
+		},
+	}).Run([]string{"run", "--verbose"})
+	expect(t, err, nil)
+	expect(t, count, 3)
+}
+
+func TestCountFlagHelpOutput(t *testing.T) {
+	fl := &CountFlag{Name: "verbose", Aliases: []string{"v"}, Usage: "increase verbosity"}
+	expect(t, fl.String(), "--verbose, -v\tincrease verbosity (default: 0)")
+}
+
+func TestParseStringFlagExpandEnv(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_HOME", "/home/app")
+
+	var tests = []struct {
+		input    string
+		expected string
+	}{
+		{"$APP_HOME/data", "/home/app/data"},
+		{"${APP_HOME}/data", "/home/app/data"},
+		{"$APP_UNSET/data", "/data"},
+		{"data", "data"},
+	}
+
+	for _, test := range tests {
+		var path, dest string
+		err := (&App{
+			Flags: []Flag{
+				&StringFlag{Name: "path", Aliases: []string{"p"}, ExpandEnv: true, Destination: &dest},
+			},
+			Action: func(ctx *Context) error {
+				path = ctx.String("p")
+				return nil
+			},
+		}).Run([]string{"run", "--path", test.input})
+		expect(t, err, nil)
+		expect(t, path, test.expected)
+		expect(t, dest, test.expected)
+	}
+}
+
+func TestParseStringFlagExpandEnvDisabled(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_HOME", "/home/app")
+
+	var path string
+	err := (&App{
+		Flags: []Flag{
+			&StringFlag{Name: "path"},
+		},
+		Action: func(ctx *Context) error {
+			path = ctx.String("path")
+			return nil
+		},
+	}).Run([]string{"run", "--path", "$APP_HOME/data"})
+	expect(t, err, nil)
+	expect(t, path, "$APP_HOME/data")
+}
+
+func TestParseNumbersWithUnderscores(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_FLOAT", "3_000.5")
+	_ = os.Setenv("APP_INT", "1_000")
+
+	var tests = []struct {
+		flag     Flag
+		args     []string
+		expected interface{}
+	}{
+		{&IntFlag{Name: "n"}, []string{"--n", "1_000"}, 1000},
+		{&IntFlag{Name: "n"}, []string{"--n", "1000"}, 1000},
+		{&IntFlag{Name: "n", EnvVars: []string{"APP_INT"}}, nil, 1000},
+		{&Int64Flag{Name: "n"}, []string{"--n", "1_000_000"}, int64(1000000)},
+		{&UintFlag{Name: "n"}, []string{"--n", "1_000"}, uint(1000)},
+		{&Uint64Flag{Name: "n", EnvVars: []string{"APP_INT"}}, nil, uint64(1000)},
+		{&Float64Flag{Name: "n"}, []string{"--n", "3_000.5"}, 3000.5},
+		{&Float64Flag{Name: "n"}, []string{"--n", "3000.5"}, 3000.5},
+		{&Float64Flag{Name: "n", EnvVars: []string{"APP_FLOAT"}}, nil, 3000.5},
+		{&IntSliceFlag{Name: "n"}, []string{"--n", "1_000", "--n", "2"}, []int{1000, 2}},
+		{&Float64SliceFlag{Name: "n"}, []string{"--n", "3_000.5"}, []float64{3000.5}},
+	}
+
+	for _, test := range tests {
+		var value interface{}
+		err := (&App{
+			Flags: []Flag{test.flag},
+			Action: func(ctx *Context) error {
+				value = ctx.Value("n")
+				return nil
+			},
+		}).Run(append([]string{"run"}, test.args...))
+		expect(t, err, nil)
+
+		switch v := value.(type) {
+		case IntSlice:
+			value = v.Value()
+		case Float64Slice:
+			value = v.Value()
+		}
+		expect(t, value, test.expected)
+	}
+}
+
+func TestStringSliceFlag_ReplaceOnSet(t *testing.T) {
+	tests := []struct {
+		replace  bool
+		args     []string
+		expected []string
+	}{
+		{replace: true, args: []string{"run", "--tag", "c"}, expected: []string{"c"}},
+		{replace: true, args: []string{"run", "--tag", "c", "--tag", "d"}, expected: []string{"c", "d"}},
+		{replace: true, args: []string{"run"}, expected: []string{"a", "b"}},
+		{replace: false, args: []string{"run", "--tag", "c"}, expected: []string{"a", "b", "c"}},
+	}
+
+	for _, test := range tests {
+		// values loaded from a config file before the app is run
+		tags := NewStringSlice()
+		_ = tags.Set("a")
+		_ = tags.Set("b")
+
+		var value []string
+		err := (&App{
+			Flags: []Flag{
+				&StringSliceFlag{Name: "tag", Destination: tags, ReplaceOnSet: test.replace},
+			},
+			Action: func(ctx *Context) error {
+				value = ctx.StringSlice("tag")
+				return nil
+			},
+		}).Run(test.args)
+		expect(t, err, nil)
+		expect(t, value, test.expected)
+		expect(t, tags.Value(), test.expected)
+	}
+}
+
+func TestFlagsFromStruct(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Clearenv()
+	_ = os.Setenv("APP_WORKERS", "8")
+
+	type options struct {
+		Verbose    bool     `cli:"verbose,alias=v,usage=log more, for debugging"`
+		Workers    int      `cli:"workers,env=APP_WORKERS"`
+		Name       string   `cli:",required"`
+		MaxRetries int      `cli:""`
+		Tags       []string `cli:"tag,alias=t"`
+		Timeout    time.Duration
+		Skipped    string `cli:"-"`
+	}
+
+	opts := &options{Name: "default", MaxRetries: 3, Tags: []string{"x"}}
+	flags, err := FlagsFromStruct(opts)
+	expect(t, err, nil)
+	expect(t, len(flags), 5)
+	expect(t, flags[0].Names(), []string{"verbose", "v"})
+	expect(t, flags[0].(*BoolFlag).Usage, "log more, for debugging")
+	expect(t, flags[2].Names(), []string{"name"})
+	expect(t, flags[2].(*StringFlag).Required, true)
+	expect(t, flags[3].Names(), []string{"max-retries"})
+
+	var tags, sliceTags []string
+	err = (&App{
+		Flags: flags,
+		Action: func(ctx *Context) error {
+			tags = append(tags, opts.Tags...)
+			sliceTags = ctx.StringSlice("t")
+			return nil
+		},
+	}).Run([]string{"run", "-v", "--name", "app", "--tag", "a,b", "--tag", "c"})
+	expect(t, err, nil)
+	expect(t, opts.Verbose, true)
+	expect(t, opts.Workers, 8)
+	expect(t, opts.Name, "app")
+	expect(t, opts.MaxRetries, 3)
+	expect(t, tags, []string{"a", "b", "c"})
+	expect(t, sliceTags, []string{"a", "b", "c"})
+	expect(t, flags[4].(*StringSliceFlag).GetValue(), "[x]")
+}
+
+func TestFlagsFromStruct_Errors(t *testing.T) {
+	type unsupported struct {
+		Ratio float32 `cli:"ratio"`
+	}
+	_, err := FlagsFromStruct(&unsupported{})
+	expect(t, err.Error(), "field Ratio of cli.unsupported: unsupported type float32")
+
+	type unknownOption struct {
+		Name string `cli:"name,short=n"`
+	}
+	_, err = FlagsFromStruct(&unknownOption{})
+	expect(t, err.Error(), `field Name of cli.unknownOption: unknown option "short" in cli tag`)
+
+	_, err = FlagsFromStruct(unsupported{})
+	expect(t, err.Error(), "FlagsFromStruct needs a non-nil pointer to a struct, got cli.unsupported")
+}
@@ -8,17 +8,21 @@ import (
 
 // UintFlag is a flag with type uint
 type UintFlag struct {
-	Name        string
-	Aliases     []string
-	Usage       string
-	EnvVars     []string
-	FilePath    string
-	Required    bool
-	Hidden      bool
-	Value       uint
-	DefaultText string
-	Destination *uint
-	HasBeenSet  bool
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	Value        uint
+	DefaultText  string
+	Destination  *uint
+	HasBeenSet   bool
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -42,6 +46,11 @@ func (f *UintFlag) IsRequired() bool {
 	return f.Required
 }
 
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *UintFlag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
 // TakesValue returns true of the flag takes a value, otherwise false
 func (f *UintFlag) TakesValue() bool {
 	return true
@@ -57,9 +66,28 @@ func (f *UintFlag) IsVisible() bool {
 	return !f.Hidden
 }
 
+// GetDeprecated returns the deprecation notice of the flag
+func (f *UintFlag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *UintFlag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *UintFlag) IsSensitive() bool {
+	return f.Sensitive
+}
+
 // Apply populates the flag given the flag set and environment
 func (f *UintFlag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
 		if val != "" {
 			valInt, err := strconv.ParseUint(val, 0, 64)
 			if err != nil {
@@ -97,6 +125,24 @@ func (c *Context) Uint(name string) uint {
 	return 0
 }
 
+// UintOr looks up the value of a UintFlag, returns fallback
+// if it was not set on the command line, from the environment or from a file
+func (c *Context) UintOr(name string, fallback uint) uint {
+	if !c.IsSet(name) {
+		return fallback
+	}
+	return c.Uint(name)
+}
+
+// TryUint looks up the value of a UintFlag, also reporting whether a flag
+// named name was found, unlike Uint which returns 0 for unknown names
+func (c *Context) TryUint(name string) (uint, bool) {
+	if fs := c.lookupFlagSet(name); fs != nil {
+		return lookupUint(name, fs), true
+	}
+	return 0, false
+}
+
 func lookupUint(name string, set *flag.FlagSet) uint {
 	f := set.Lookup(name)
 	if f != nil {
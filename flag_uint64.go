@@ -8,17 +8,21 @@ import (
 
 // Uint64Flag is a flag with type uint64
 type Uint64Flag struct {
-	Name        string
-	Aliases     []string
-	Usage       string
-	EnvVars     []string
-	FilePath    string
-	Required    bool
-	Hidden      bool
-	Value       uint64
-	DefaultText string
-	Destination *uint64
-	HasBeenSet  bool
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	Value        uint64
+	DefaultText  string
+	Destination  *uint64
+	HasBeenSet   bool
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -42,6 +46,11 @@ func (f *Uint64Flag) IsRequired() bool {
 	return f.Required
 }
 
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *Uint64Flag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
 // TakesValue returns true of the flag takes a value, otherwise false
 func (f *Uint64Flag) TakesValue() bool {
 	return true
@@ -57,9 +66,28 @@ func (f *Uint64Flag) IsVisible() bool {
 	return !f.Hidden
 }
 
+// GetDeprecated returns the deprecation notice of the flag
+func (f *Uint64Flag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *Uint64Flag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *Uint64Flag) IsSensitive() bool {
+	return f.Sensitive
+}
+
 // Apply populates the flag given the flag set and environment
 func (f *Uint64Flag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
 		if val != "" {
 			valInt, err := strconv.ParseUint(val, 0, 64)
 			if err != nil {
@@ -97,6 +125,24 @@ func (c *Context) Uint64(name string) uint64 {
 	return 0
 }
 
+// Uint64Or looks up the value of a Uint64Flag, returns fallback
+// if it was not set on the command line, from the environment or from a file
+func (c *Context) Uint64Or(name string, fallback uint64) uint64 {
+	if !c.IsSet(name) {
+		return fallback
+	}
+	return c.Uint64(name)
+}
+
+// TryUint64 looks up the value of a Uint64Flag, also reporting whether a flag
+// named name was found, unlike Uint64 which returns 0 for unknown names
+func (c *Context) TryUint64(name string) (uint64, bool) {
+	if fs := c.lookupFlagSet(name); fs != nil {
+		return lookupUint64(name, fs), true
+	}
+	return 0, false
+}
+
 func lookupUint64(name string, set *flag.FlagSet) uint64 {
 	f := set.Lookup(name)
 	if f != nil {
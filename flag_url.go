@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// urlValue wraps a url.URL to satisfy flag.Value
+type urlValue struct {
+	url            *url.URL
+	allowedSchemes []string
+}
+
+func newURLValue(val *url.URL, p *url.URL, allowedSchemes []string) *urlValue {
+	if val != nil {
+		*p = *val
+	}
+	return &urlValue{url: p, allowedSchemes: allowedSchemes}
+}
+
+// Set parses the value as an absolute URL
+func (u *urlValue) Set(value string) error {
+	parsed, err := parseURL(value, u.allowedSchemes)
+	if err != nil {
+		return err
+	}
+	*u.url = *parsed
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (u *urlValue) String() string {
+	if u == nil || u.value() == nil {
+		return ""
+	}
+	return u.url.String()
+}
+
+// Get returns the *url.URL set by this flag
+func (u *urlValue) Get() interface{} {
+	return u.value()
+}
+
+func (u *urlValue) value() *url.URL {
+	if *u.url == (url.URL{}) {
+		return nil
+	}
+	parsed := *u.url
+	return &parsed
+}
+
+// parseURL parses value as a URL with a scheme and a host, and with one of
+// allowedSchemes as its scheme if there are any.
+func parseURL(value string, allowedSchemes []string) (*url.URL, error) {
+	parsed, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("URL %q must have a scheme and a host", value)
+	}
+	if len(allowedSchemes) == 0 {
+		return parsed, nil
+	}
+	for _, scheme := range allowedSchemes {
+		if strings.EqualFold(scheme, parsed.Scheme) {
+			return parsed, nil
+		}
+	}
+	return nil, fmt.Errorf("URL %q must have one of the schemes %s", value, strings.Join(allowedSchemes, ", "))
+}
+
+// URLFlag is a flag with type *url.URL
+type URLFlag struct {
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	FilePath     string
+	Required     bool
+	RequiredWhen func(*Context) bool
+	Hidden       bool
+	Deprecated   string
+	Sensitive    bool
+	Category     string
+	Value        *url.URL
+	DefaultText  string
+	Destination  *url.URL
+	HasBeenSet   bool
+
+	// AllowedSchemes restricts the schemes accepted for the URL, e.g.
+	// []string{"https"}. Any scheme is accepted when it is empty.
+	AllowedSchemes []string
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *URLFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *URLFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *URLFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *URLFlag) IsRequired() bool {
+	return f.Required
+}
+
+// IsRequiredWhen returns whether or not the flag is required in the context
+func (f *URLFlag) IsRequiredWhen(ctx *Context) bool {
+	return f.RequiredWhen != nil && f.RequiredWhen(ctx)
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *URLFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *URLFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *URLFlag) GetValue() string {
+	if f.Value != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// IsVisible returns true if the flag is not hidden, otherwise false
+func (f *URLFlag) IsVisible() bool {
+	return !f.Hidden
+}
+
+// GetDeprecated returns the deprecation notice of the flag
+func (f *URLFlag) GetDeprecated() string {
+	return f.Deprecated
+}
+
+// GetCategory returns the category of the flag in help
+func (f *URLFlag) GetCategory() string {
+	return f.Category
+}
+
+// IsSensitive returns true if the value of the flag is masked, otherwise false
+func (f *URLFlag) IsSensitive() bool {
+	return f.Sensitive
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *URLFlag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		if val != "" {
+			parsed, err := parseURL(val, f.AllowedSchemes)
+			if err != nil {
+				return fmt.Errorf("could not parse %q as URL value for flag %s: %s", val, f.Name, err)
+			}
+
+			f.Value = parsed
+			f.HasBeenSet = true
+		}
+	}
+
+	for _, name := range f.Names() {
+		if f.Destination != nil {
+			set.Var(newURLValue(f.Value, f.Destination, f.AllowedSchemes), name, f.Usage)
+			continue
+		}
+		set.Var(newURLValue(f.Value, new(url.URL), f.AllowedSchemes), name, f.Usage)
+	}
+
+	return nil
+}
+
+// URL looks up the value of a local URLFlag, returns
+// nil if not found
+func (c *Context) URL(name string) *url.URL {
+	if fs := c.lookupFlagSet(name); fs != nil {
+		return lookupURL(name, fs)
+	}
+	return nil
+}
+
+func lookupURL(name string, set *flag.FlagSet) *url.URL {
+	f := set.Lookup(name)
+	if f != nil {
+		if u, ok := f.Value.(*urlValue); ok {
+			return u.value()
+		}
+	}
+	return nil
+}
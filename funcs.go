@@ -1,8 +1,18 @@
 package cli
 
-// BashCompleteFunc is an action to execute when the shell completion flag is set
+// BashCompleteFunc is an action to execute when the shell completion flag is set.
+//
+// The flags typed before the token being completed are parsed into the
+// Context, so that e.g. the values offered for --branch can depend on a
+// --repo given earlier. This is best-effort: parsing stops at the first
+// argument that cannot be parsed, such as a partially typed flag or a flag
+// still missing its value, and flags after it are not set.
 type BashCompleteFunc func(*Context)
 
+// FlagCompleteFunc returns the candidate values of a flag when the shell is
+// completing its value. The Context is parsed as for a BashCompleteFunc.
+type FlagCompleteFunc func(*Context) []string
+
 // BeforeFunc is an action to execute before any subcommands are run, but after
 // the context is ready if a non-nil error is returned, no subcommands are run
 type BeforeFunc func(*Context) error
@@ -11,12 +21,27 @@ type BeforeFunc func(*Context) error
 // subcommand has finished it is run even if Action() panics
 type AfterFunc func(*Context) error
 
+// ArgsRewriterFunc transforms the arguments of an App, without the program
+// name, before they are parsed, e.g. to expand user defined aliases
+type ArgsRewriterFunc func(args []string) []string
+
 // ActionFunc is the action to execute when no subcommands are specified
 type ActionFunc func(*Context) error
 
+// MiddlewareFunc wraps the action of a command, e.g. to time it or check
+// permissions, and returns the action to run instead. The returned action can
+// skip the command by not calling next.
+type MiddlewareFunc func(next ActionFunc) ActionFunc
+
 // CommandNotFoundFunc is executed if the proper command cannot be found
 type CommandNotFoundFunc func(*Context, string)
 
+// OnCommandNotFoundFunc is executed if the proper command cannot be found,
+// after any CommandNotFoundFunc. It is passed the error that would be returned
+// otherwise and the error it returns is returned instead, so that it can turn
+// an unknown command into an error, replace the error or return nil.
+type OnCommandNotFoundFunc func(context *Context, command string, err error) error
+
 // OnUsageErrorFunc is executed if a usage error occurs. This is useful for displaying
 // customized usage error messages.  This function is able to replace the
 // original error messages.  If this function is not set, the "Incorrect usage"
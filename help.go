@@ -64,6 +64,17 @@ var HelpPrinterCustom helpPrinterCustom = printHelpCustom
 // VersionPrinter prints the version for the App
 var VersionPrinter = printVersion
 
+// HelpHeaderStyle styles the section headings of help, e.g. "OPTIONS:", for
+// instance to make them bold with ANSI escape codes. Headings are left alone
+// when it is nil or when help is not written to a terminal, so that help
+// stays plain text in pipes and files.
+var HelpHeaderStyle func(string) string
+
+// HelpFlagStyle styles the names of flags and their placeholder in help,
+// e.g. "--config FILE, -c FILE". Like HelpHeaderStyle, it is only applied
+// when help is written to a terminal.
+var HelpFlagStyle func(string) string
+
 // ShowAppHelpAndExit - Prints the list of subcommands for the app and exits with exit code.
 func ShowAppHelpAndExit(c *Context, exitCode int) {
 	_ = ShowAppHelp(c)
@@ -138,6 +149,9 @@ func printFlagSuggestions(lastArg string, flags []Flag, writer io.Writer) {
 		if bflag, ok := flag.(*BoolFlag); ok && bflag.Hidden {
 			continue
 		}
+		if sflag, ok := flag.(*StringFlag); ok && sflag.EnvOnly {
+			continue
+		}
 		for _, name := range flag.Names() {
 			name = strings.TrimSpace(name)
 			// this will get total count utf8 letters in flag name
@@ -159,6 +173,36 @@ func printFlagSuggestions(lastArg string, flags []Flag, writer io.Writer) {
 	}
 }
 
+// printFlagValueCompletions prints the value completions of the flag given
+// just before the completion flag, if it is one of flags and has a
+// CompletionFunc. It returns false if no completions were printed.
+func printFlagValueCompletions(c *Context, flags []Flag) bool {
+	if len(os.Args) <= 2 {
+		return false
+	}
+	lastArg := os.Args[len(os.Args)-2]
+	if !strings.HasPrefix(lastArg, "-") || strings.Contains(lastArg, "=") {
+		return false
+	}
+	name := strings.TrimPrefix(strings.TrimPrefix(lastArg, "-"), "-")
+
+	for _, f := range flags {
+		cf, ok := f.(CompletionFlag)
+		if !ok || cf.GetCompletionFunc() == nil {
+			continue
+		}
+		for _, n := range f.Names() {
+			if n == name {
+				for _, value := range cf.GetCompletionFunc()(c) {
+					_, _ = fmt.Fprintln(c.App.Writer, value)
+				}
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func DefaultCompleteWithFlags(cmd *Command) func(c *Context) {
 	return func(c *Context) {
 		if len(os.Args) > 2 {
@@ -189,7 +233,12 @@ func ShowCommandHelpAndExit(c *Context, command string, code int) {
 func ShowCommandHelp(ctx *Context, command string) error {
 	// show the subcommand help for a command with subcommands
 	if command == "" {
-		HelpPrinter(ctx.App.Writer, SubcommandHelpTemplate, ctx.App)
+		templ := ctx.App.CustomAppHelpTemplate
+		if templ == "" {
+			templ = SubcommandHelpTemplate
+		}
+
+		HelpPrinter(ctx.App.Writer, templ, ctx.App)
 		return nil
 	}
 
@@ -206,12 +255,11 @@ func ShowCommandHelp(ctx *Context, command string) error {
 		}
 	}
 
+	var err error
 	if ctx.App.CommandNotFound == nil {
-		return Exit(fmt.Sprintf("No help topic for '%v'", command), 3)
+		err = Exit(fmt.Sprintf("No help topic for '%v'", command), 3)
 	}
-
-	ctx.App.CommandNotFound(ctx, command)
-	return nil
+	return ctx.App.commandNotFound(ctx, command, err)
 }
 
 // ShowSubcommandHelpAndExit - Prints help for the given subcommand and exits with exit code.
@@ -233,8 +281,13 @@ func ShowSubcommandHelp(c *Context) error {
 	return ShowCommandHelp(c, "")
 }
 
-// ShowVersion prints the version number of the App
+// ShowVersion prints the version number of the App, using the
+// VersionPrinter of the App if it has one
 func ShowVersion(c *Context) {
+	if c.App != nil && c.App.VersionPrinter != nil {
+		c.App.VersionPrinter(c)
+		return
+	}
 	VersionPrinter(c)
 }
 
@@ -245,6 +298,9 @@ func printVersion(c *Context) {
 // ShowCompletions prints the lists of commands within a given context
 func ShowCompletions(c *Context) {
 	a := c.App
+	if a != nil && printFlagValueCompletions(c, a.Flags) {
+		return
+	}
 	if a != nil && a.BashComplete != nil {
 		a.BashComplete(c)
 	}
@@ -254,6 +310,9 @@ func ShowCompletions(c *Context) {
 func ShowCommandCompletions(ctx *Context, command string) {
 	c := ctx.App.Command(command)
 	if c != nil {
+		if printFlagValueCompletions(ctx, c.Flags) {
+			return
+		}
 		if c.BashComplete != nil {
 			c.BashComplete(ctx)
 		} else {
@@ -278,10 +337,20 @@ func printHelpCustom(out io.Writer, templ string, data interface{}, customFuncs
 		funcMap[key] = value
 	}
 
-	w := tabwriter.NewWriter(out, 1, 8, 2, ' ', 0)
+	// headings and flags are styled once the output is complete and
+	// aligned, as templates can put them anywhere and the escape codes of
+	// styles must not count towards the width of columns
+	styled := isTerminal(out) && (HelpHeaderStyle != nil || HelpFlagStyle != nil)
+	dst := out
+	var buf strings.Builder
+	if styled {
+		dst = &buf
+	}
+
+	w := tabwriter.NewWriter(dst, 1, 8, 2, ' ', 0)
 	t := template.Must(template.New("help").Funcs(funcMap).Parse(templ))
 
-	err := t.Execute(w, data)
+	err := t.Execute(w, withoutFlagHints(data))
 	if err != nil {
 		// If the writer is closed, t.Execute will fail, and there's nothing
 		// we can do to recover.
@@ -291,6 +360,147 @@ func printHelpCustom(out io.Writer, templ string, data interface{}, customFuncs
 		return
 	}
 	_ = w.Flush()
+
+	if styled {
+		help := buf.String()
+		if HelpHeaderStyle != nil {
+			help = styleHelpHeaders(help, HelpHeaderStyle)
+		}
+		if HelpFlagStyle != nil {
+			help = styleHelpFlags(help, HelpFlagStyle)
+		}
+		_, _ = io.WriteString(out, help)
+	}
+}
+
+// withoutFlagHints returns data for the help of an App or Command with
+// HideFlagHints as a hintlessApp or hintlessCommand, and data otherwise
+func withoutFlagHints(data interface{}) interface{} {
+	switch data := data.(type) {
+	case *App:
+		if data.HideFlagHints {
+			return &hintlessApp{data}
+		}
+	case *Command:
+		if data.hideFlagHints {
+			return &hintlessCommand{data}
+		}
+	}
+	return data
+}
+
+// hintlessApp is an App whose visible flags leave their environment
+// variable and file hints out of help
+type hintlessApp struct {
+	*App
+}
+
+func (a *hintlessApp) VisibleFlags() []Flag {
+	return hintlessFlags(a.App.VisibleFlags())
+}
+
+func (a *hintlessApp) VisibleFlagCategories() []VisibleFlagCategory {
+	return hintlessFlagCategories(a.App.VisibleFlagCategories())
+}
+
+// hintlessCommand is a Command whose visible flags leave their environment
+// variable and file hints out of help
+type hintlessCommand struct {
+	*Command
+}
+
+func (c *hintlessCommand) VisibleFlags() []Flag {
+	return hintlessFlags(c.Command.VisibleFlags())
+}
+
+func (c *hintlessCommand) VisibleFlagCategories() []VisibleFlagCategory {
+	return hintlessFlagCategories(c.Command.VisibleFlagCategories())
+}
+
+// hintlessFlag is a Flag whose help line leaves out its environment
+// variable and file hints
+type hintlessFlag struct {
+	Flag
+}
+
+func (f *hintlessFlag) String() string {
+	return stringifyFlagHints(f.Flag, false)
+}
+
+func hintlessFlags(fl []Flag) []Flag {
+	flags := make([]Flag, len(fl))
+	for i, f := range fl {
+		flags[i] = &hintlessFlag{f}
+	}
+	return flags
+}
+
+func hintlessFlagCategories(categories []VisibleFlagCategory) []VisibleFlagCategory {
+	var ret []VisibleFlagCategory
+	for _, c := range categories {
+		ret = append(ret, &visibleFlagCategory{name: c.Name(), flags: hintlessFlags(c.Flags())})
+	}
+	return ret
+}
+
+// styleHelpHeaders applies style to the lines of help that are headings,
+// that is lines in capitals that end with a colon and are not indented.
+func styleHelpHeaders(help string, style func(string) string) string {
+	lines := strings.Split(help, "\n")
+	for i, line := range lines {
+		if isHelpHeader(line) {
+			lines[i] = style(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// styleHelpFlags applies style to the names of flags in help, that is the
+// start of the indented lines beginning with "-" or "--" and a name, up to
+// the gap before the usage of the flag.
+func styleHelpFlags(help string, style func(string) string) string {
+	lines := strings.Split(help, "\n")
+	for i, line := range lines {
+		names := strings.TrimLeft(line, " ")
+		if !isHelpFlag(names) {
+			continue
+		}
+		indent := line[:len(line)-len(names)]
+		usage := ""
+		if j := strings.Index(names, "  "); j >= 0 {
+			names, usage = names[:j], names[j:]
+		}
+		lines[i] = indent + style(names) + usage
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isHelpFlag(line string) bool {
+	name := strings.TrimPrefix(strings.TrimPrefix(line, "-"), "-")
+	return len(line) > len(name) && name != "" && name[0] != ' ' && name[0] != '-'
+}
+
+func isHelpHeader(line string) bool {
+	if len(line) < 2 || !strings.HasSuffix(line, ":") || line[0] < 'A' || line[0] > 'Z' {
+		return false
+	}
+	for _, r := range line[:len(line)-1] {
+		if (r < 'A' || r > 'Z') && r != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+// isTerminal reports whether w is a terminal, as opposed to a pipe, a file
+// or a buffer
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
 }
 
 func printHelp(out io.Writer, templ string, data interface{}) {
@@ -299,7 +509,7 @@ func printHelp(out io.Writer, templ string, data interface{}) {
 
 func checkVersion(c *Context) bool {
 	found := false
-	for _, name := range VersionFlag.Names() {
+	for _, name := range c.App.versionFlag().Names() {
 		if c.Bool(name) {
 			found = true
 		}
@@ -307,9 +517,9 @@ func checkVersion(c *Context) bool {
 	return found
 }
 
-func checkHelp(c *Context) bool {
+func checkHelp(c *Context, helpFlag Flag) bool {
 	found := false
-	for _, name := range HelpFlag.Names() {
+	for _, name := range helpFlag.Names() {
 		if c.Bool(name) {
 			found = true
 		}
@@ -317,8 +527,8 @@ func checkHelp(c *Context) bool {
 	return found
 }
 
-func checkCommandHelp(c *Context, name string) bool {
-	if c.Bool("h") || c.Bool("help") {
+func checkCommandHelp(c *Context, name string, helpFlag Flag) bool {
+	if checkHelp(c, helpFlag) {
 		_ = ShowCommandHelp(c, name)
 		return true
 	}
@@ -326,8 +536,8 @@ func checkCommandHelp(c *Context, name string) bool {
 	return false
 }
 
-func checkSubcommandHelp(c *Context) bool {
-	if c.Bool("h") || c.Bool("help") {
+func checkSubcommandHelp(c *Context, helpFlag Flag) bool {
+	if checkHelp(c, helpFlag) {
 		_ = ShowSubcommandHelp(c)
 		return true
 	}
@@ -0,0 +1,107 @@
+package cli
+
+import "reflect"
+
+// HelpData is the information shown in the help of an App or a Command,
+// structured for rendering help in a custom format, e.g. as JSON. It is read
+// from the same fields as the help templates, which are rendered with the
+// App or Command itself rather than with HelpData so that custom templates
+// keep working.
+type HelpData struct {
+	Name        string
+	HelpName    string
+	Aliases     []string
+	Usage       string
+	UsageText   string
+	ArgsUsage   string
+	Description string
+	Category    string
+	Hidden      bool
+	Flags       []FlagHelpData
+	Subcommands []*HelpData
+}
+
+// FlagHelpData is the information shown in the help of a Flag.
+type FlagHelpData struct {
+	Names       []string
+	Usage       string
+	Default     string
+	DefaultText string
+	EnvVars     []string
+	Category    string
+	TakesValue  bool
+	Required    bool
+	Hidden      bool
+}
+
+// HelpData returns the help information of the App, its flags and its
+// commands, including hidden ones, without printing it.
+func (a *App) HelpData() *HelpData {
+	return &HelpData{
+		Name:        a.Name,
+		HelpName:    a.HelpName,
+		Usage:       a.Usage,
+		UsageText:   a.UsageText,
+		ArgsUsage:   a.ArgsUsage,
+		Description: a.Description,
+		Flags:       flagsHelpData(a.Flags),
+		Subcommands: commandsHelpData(a.Commands),
+	}
+}
+
+// HelpData returns the help information of the Command, its flags and its
+// subcommands, including hidden ones, without printing it.
+func (c *Command) HelpData() *HelpData {
+	return &HelpData{
+		Name:        c.Name,
+		HelpName:    c.HelpName,
+		Aliases:     c.Aliases,
+		Usage:       c.Usage,
+		UsageText:   c.UsageText,
+		ArgsUsage:   c.ArgsUsage,
+		Description: c.Description,
+		Category:    c.Category,
+		Hidden:      c.Hidden,
+		Flags:       flagsHelpData(c.Flags),
+		Subcommands: commandsHelpData(c.Subcommands),
+	}
+}
+
+func commandsHelpData(commands []*Command) []*HelpData {
+	var data []*HelpData
+	for _, command := range commands {
+		data = append(data, command.HelpData())
+	}
+	return data
+}
+
+func flagsHelpData(flags []Flag) []FlagHelpData {
+	var data []FlagHelpData
+	for _, f := range flags {
+		data = append(data, newFlagHelpData(f))
+	}
+	return data
+}
+
+func newFlagHelpData(f Flag) FlagHelpData {
+	data := FlagHelpData{Names: f.Names()}
+	if df, ok := f.(DocGenerationFlag); ok {
+		data.Usage = df.GetUsage()
+		data.Default = df.GetValue()
+		data.TakesValue = df.TakesValue()
+	}
+	if fv := flagValue(f); fv.Kind() == reflect.Struct {
+		data.EnvVars = flagStringSliceField(f, "EnvVars")
+		data.Category = flagCategory(f)
+		if defaultText := fv.FieldByName("DefaultText"); defaultText.IsValid() {
+			data.DefaultText = defaultText.String()
+		}
+	}
+	if rf, ok := f.(RequiredFlag); ok {
+		data.Required = rf.IsRequired()
+	}
+	if vf, ok := f.(VisibleFlag); ok {
+		data.Hidden = !vf.IsVisible()
+	}
+	return data
+}
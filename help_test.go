@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
 	"runtime"
 	"strings"
 	"testing"
+	"text/template"
+	"time"
 )
 
 func Test_ShowAppHelp_NoAuthor(t *testing.T) {
@@ -491,6 +494,52 @@ EXAMPLES:
 	}
 }
 
+func TestShowCommandHelp_CustomTemplateWithSubcommands(t *testing.T) {
+	template := `{{with .HelpData}}{{.HelpName}}: {{.Usage}}
+{{range .Subcommands}}{{if not .Hidden}}  {{.Name}}: {{.Usage}}
+{{end}}{{end}}{{end}}EXAMPLES:
+  remote add origin URL
+`
+
+	for _, args := range [][]string{
+		{"git", "help", "remote"},
+		{"git", "remote", "--help"},
+	} {
+		output := &bytes.Buffer{}
+		app := &App{
+			Name:   "git",
+			Writer: output,
+			Commands: []*Command{
+				{
+					Name:               "remote",
+					HelpName:           "git remote",
+					Usage:              "manage remotes",
+					CustomHelpTemplate: template,
+					HideHelpCommand:    true,
+					Subcommands: []*Command{
+						{Name: "add", Usage: "add a remote"},
+						{Name: "remove", Usage: "remove a remote"},
+						{Name: "prune", Usage: "prune a remote", Hidden: true},
+					},
+				},
+			},
+		}
+
+		err := app.Run(args)
+		expect(t, err, nil)
+
+		expected := `git remote: manage remotes
+  add: add a remote
+  remove: remove a remote
+EXAMPLES:
+  remote add origin URL
+`
+		if !strings.HasPrefix(output.String(), expected) {
+			t.Errorf("expected output for %v to start with %q, got %q", args, expected, output.String())
+		}
+	}
+}
+
 func TestShowSubcommandHelp_CommandUsageText(t *testing.T) {
 	app := &App{
 		Commands: []*Command{
@@ -1037,3 +1086,323 @@ func TestHideHelpCommand_WithSubcommands(t *testing.T) {
 		t.Errorf("Run returned unexpected error: %v", err)
 	}
 }
+
+func TestApp_HelpData(t *testing.T) {
+	app := &App{
+		Name:  "mycli",
+		Usage: "does things",
+		Flags: []Flag{
+			&StringFlag{Name: "config", Aliases: []string{"c"}, Usage: "load `FILE`", Value: "cli.yaml", EnvVars: []string{"MYCLI_CONFIG"}},
+		},
+		Commands: []*Command{{
+			Name:    "remote",
+			Aliases: []string{"r"},
+			Usage:   "manage remotes",
+			Subcommands: []*Command{{
+				Name:  "add",
+				Usage: "add a remote",
+				Flags: []Flag{
+					&StringFlag{Name: "url", Required: true},
+					&IntFlag{Name: "timeout", Hidden: true, Value: 3, DefaultText: "3s"},
+				},
+				Subcommands: []*Command{{Name: "mirror", Hidden: true}},
+			}},
+		}},
+	}
+
+	data := app.HelpData()
+	expect(t, data.Name, "mycli")
+	expect(t, data.Usage, "does things")
+	expect(t, data.Flags, []FlagHelpData{{
+		Names:      []string{"config", "c"},
+		Usage:      "load `FILE`",
+		Default:    "cli.yaml",
+		EnvVars:    []string{"MYCLI_CONFIG"},
+		TakesValue: true,
+	}})
+
+	expect(t, len(data.Subcommands), 1)
+	remote := data.Subcommands[0]
+	expect(t, remote.Name, "remote")
+	expect(t, remote.Aliases, []string{"r"})
+
+	expect(t, len(remote.Subcommands), 1)
+	add := remote.Subcommands[0]
+	expect(t, add.Usage, "add a remote")
+	expect(t, add.Flags, []FlagHelpData{
+		{Names: []string{"url"}, TakesValue: true, Required: true},
+		{Names: []string{"timeout"}, Default: "3", DefaultText: "3s", TakesValue: true, Hidden: true},
+	})
+
+	expect(t, len(add.Subcommands), 1)
+	expect(t, add.Subcommands[0].Name, "mirror")
+	expect(t, add.Subcommands[0].Hidden, true)
+}
+
+func TestHelpData_MatchesHelp(t *testing.T) {
+	output := new(bytes.Buffer)
+	app := &App{
+		Name:   "mycli",
+		Usage:  "does things",
+		Writer: output,
+		Flags: []Flag{
+			&StringFlag{Name: "config", Aliases: []string{"c"}, Usage: "load config", Value: "cli.yaml"},
+			&IntFlag{Name: "workers", Usage: "number of workers", Value: 4},
+			&DurationFlag{Name: "timeout", Usage: "give up after", Value: time.Second, DefaultText: "one second"},
+		},
+		Commands: []*Command{{Name: "remote", Usage: "manage remotes"}},
+	}
+	_ = app.Run([]string{"mycli", "--help"})
+	help := output.String()
+
+	data := app.HelpData()
+	for _, s := range []string{data.Name, data.Usage, data.Subcommands[0].Name, data.Subcommands[0].Usage} {
+		if !strings.Contains(help, s) {
+			t.Errorf("expected %q in help %q", s, help)
+		}
+	}
+	for _, f := range data.Flags {
+		def := f.Default
+		if f.DefaultText != "" {
+			def = f.DefaultText
+		}
+		for _, s := range append([]string{f.Usage, def}, f.Names...) {
+			if !strings.Contains(help, s) {
+				t.Errorf("expected %q of flag %s in help %q", s, f.Names[0], help)
+			}
+		}
+	}
+}
+
+func TestShowAppHelp_FlagHints(t *testing.T) {
+	for _, hide := range []bool{false, true} {
+		output := new(bytes.Buffer)
+		app := &App{
+			Name:          "myapp",
+			Writer:        output,
+			HideFlagHints: hide,
+			Flags: []Flag{
+				&IntFlag{Name: "workers", Usage: "number of workers", EnvVars: []string{"MYAPP_WORKERS"}, FilePath: "/etc/myapp/workers"},
+			},
+			Commands: []*Command{{
+				Name: "run",
+				Flags: []Flag{
+					&StringFlag{Name: "queue", EnvVars: []string{"MYAPP_QUEUE"}},
+				},
+				Subcommands: []*Command{{Name: "once"}},
+			}, {
+				Name: "stop",
+				Flags: []Flag{
+					&DurationFlag{Name: "grace", EnvVars: []string{"MYAPP_GRACE"}},
+				},
+			}},
+		}
+
+		_ = app.Run([]string{"myapp", "--help"})
+		_ = app.Run([]string{"myapp", "run", "--help"})
+		_ = app.Run([]string{"myapp", "help", "stop"})
+
+		for _, hint := range []string{"MYAPP_WORKERS", "[/etc/myapp/workers]", "MYAPP_QUEUE", "MYAPP_GRACE"} {
+			if got := strings.Contains(output.String(), hint); got == hide {
+				t.Errorf("expected hint %q to be shown %v, got output: %s", hint, !hide, output.String())
+			}
+		}
+	}
+
+	// hiding the hints of an App leaves other flags alone
+	if got := (&IntFlag{Name: "workers", EnvVars: []string{"MYAPP_WORKERS"}}).String(); !strings.Contains(got, "MYAPP_WORKERS") {
+		t.Errorf("expected env hint in %q", got)
+	}
+}
+
+func TestShowAppHelp_DefaultTemplatesCustomPrinter(t *testing.T) {
+	old := HelpPrinter
+	defer func() { HelpPrinter = old }()
+
+	output := new(bytes.Buffer)
+	HelpPrinter = func(w io.Writer, templ string, data interface{}) {
+		funcs := template.FuncMap{"join": strings.Join, "nindent": nindent, "trim": strings.TrimSpace}
+		_ = template.Must(template.New("help").Funcs(funcs).Parse(templ)).Execute(w, data)
+	}
+
+	app := &App{
+		Name:          "myapp",
+		Writer:        output,
+		HideFlagHints: true,
+		Flags:         []Flag{&IntFlag{Name: "workers", EnvVars: []string{"MYAPP_WORKERS"}}},
+		Commands:      []*Command{{Name: "run", Flags: []Flag{&StringFlag{Name: "queue"}}}},
+	}
+	_ = app.Run([]string{"myapp", "--help"})
+	_ = app.Run([]string{"myapp", "help", "run"})
+
+	for _, s := range []string{"--workers value", "--queue value"} {
+		if !strings.Contains(output.String(), s) {
+			t.Errorf("expected %q in help %q", s, output.String())
+		}
+	}
+}
+
+func TestShowHelp_SortFlags(t *testing.T) {
+	flags := func() []Flag {
+		return []Flag{
+			&StringFlag{Name: "zone"},
+			&BoolFlag{Name: "debug"},
+			&IntFlag{Name: "Workers"},
+			&StringFlag{Name: "api-key"},
+		}
+	}
+
+	tests := []struct {
+		sort bool
+		want []string
+	}{
+		{sort: false, want: []string{"--zone", "--debug", "--Workers", "--api-key", "--help"}},
+		{sort: true, want: []string{"--api-key", "--debug", "--help", "--Workers", "--zone"}},
+	}
+
+	for _, test := range tests {
+		for _, args := range [][]string{{"app", "--help"}, {"app", "cmd", "--help"}} {
+			output := new(bytes.Buffer)
+			app := &App{
+				Name:        "app",
+				Writer:      output,
+				HideVersion: true,
+				SortFlags:   test.sort,
+				Flags:       flags(),
+				Commands:    []*Command{{Name: "cmd", Flags: flags()}},
+			}
+			_ = app.Run(args)
+
+			last := -1
+			for _, name := range test.want {
+				i := strings.Index(output.String(), "   "+name)
+				if i <= last {
+					t.Errorf("expected flags in order %v for %v with SortFlags %v, got: %s", test.want, args, test.sort, output.String())
+					break
+				}
+				last = i
+			}
+		}
+	}
+}
+
+func TestShowHelp_FlagCategories(t *testing.T) {
+	flags := func() []Flag {
+		return []Flag{
+			&StringFlag{Name: "listen", Category: "Networking"},
+			&StringFlag{Name: "log-level", Category: "Logging"},
+			&BoolFlag{Name: "dry-run"},
+			&StringFlag{Name: "log-file", Category: "Logging"},
+			&IntFlag{Name: "port", Category: "Networking"},
+			&StringFlag{Name: "secret", Category: "Networking", Hidden: true},
+		}
+	}
+
+	output := new(bytes.Buffer)
+	app := &App{
+		Name:        "app",
+		Writer:      output,
+		HideVersion: true,
+		Flags:       flags(),
+		Commands:    []*Command{{Name: "cmd", Flags: flags()}},
+	}
+
+	_ = app.Run([]string{"app", "--help"})
+	expect(t, strings.Contains(output.String(), `GLOBAL OPTIONS:
+   --dry-run   (default: false)
+   --help, -h  show help (default: false)
+   Logging:
+     --log-level value  
+     --log-file value   
+   Networking:
+     --listen value  
+     --port value    (default: 0)
+`), true)
+
+	output.Reset()
+	_ = app.Run([]string{"app", "cmd", "--help"})
+	expect(t, strings.Contains(output.String(), `OPTIONS:
+   --dry-run   (default: false)
+   --help, -h  show help (default: false)
+   Logging:
+     --log-level value  
+     --log-file value   
+   Networking:
+     --listen value  
+     --port value    (default: 0)
+`), true)
+	expect(t, strings.Contains(output.String(), "--secret"), false)
+}
+
+func TestVisibleFlagCategories_NoCategories(t *testing.T) {
+	app := &App{Flags: []Flag{&BoolFlag{Name: "debug"}}}
+	expect(t, len(app.VisibleFlagCategories()), 0)
+}
+
+func TestVisibleFlagCategories_CategorizableFlag(t *testing.T) {
+	app := &App{Flags: []Flag{
+		&BoolFlag{Name: "debug"},
+		&debuggingFlag{&BoolFlag{Name: "trace"}},
+	}}
+	categories := app.VisibleFlagCategories()
+	expect(t, len(categories), 2)
+	expect(t, categories[1].Name(), "Debugging")
+	expect(t, categories[1].Flags()[0].Names(), []string{"trace"})
+}
+
+// debuggingFlag is a BoolFlag in the "Debugging" category
+type debuggingFlag struct {
+	*BoolFlag
+}
+
+func (f *debuggingFlag) GetCategory() string {
+	return "Debugging"
+}
+
+func TestHelpStyles_NotTerminal(t *testing.T) {
+	defer func(header, flag func(string) string) {
+		HelpHeaderStyle, HelpFlagStyle = header, flag
+	}(HelpHeaderStyle, HelpFlagStyle)
+	HelpHeaderStyle = func(s string) string { return "\x1b[1m" + s + "\x1b[0m" }
+	HelpFlagStyle = func(s string) string { return "\x1b[36m" + s + "\x1b[0m" }
+
+	file, err := ioutil.TempFile("", "help")
+	expect(t, err, nil)
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	var buf bytes.Buffer
+	for _, w := range []io.Writer{&buf, file} {
+		app := &App{
+			Name:   "greet",
+			Writer: w,
+			Flags: []Flag{
+				&StringFlag{Name: "config", Aliases: []string{"c"}, Usage: "load `FILE`"},
+			},
+		}
+		_ = app.Run([]string{"greet", "--help"})
+	}
+
+	contents, err := ioutil.ReadFile(file.Name())
+	expect(t, err, nil)
+	for _, out := range []string{buf.String(), string(contents)} {
+		if !strings.Contains(out, "GLOBAL OPTIONS:") || !strings.Contains(out, "--config FILE, -c FILE") {
+			t.Errorf("expected plain help, got %q", out)
+		}
+		if strings.Contains(out, "\x1b") {
+			t.Errorf("expected no escape codes, got %q", out)
+		}
+	}
+}
+
+func TestStyleHelpHeaders(t *testing.T) {
+	help := "NAME:\n   greet\n\nGLOBAL OPTIONS:\n   misc:\n     --help, -h  show help (default: false)\nnot a heading:\n"
+	styled := styleHelpHeaders(help, func(s string) string { return "<" + s + ">" })
+	expect(t, styled, "<NAME:>\n   greet\n\n<GLOBAL OPTIONS:>\n   misc:\n     --help, -h  show help (default: false)\nnot a heading:\n")
+}
+
+func TestStyleHelpFlags(t *testing.T) {
+	help := "OPTIONS:\n   --config FILE, -c FILE  load FILE\n   --help, -h\n   - not a flag\n   -- neither\n"
+	styled := styleHelpFlags(help, func(s string) string { return "<" + s + ">" })
+	expect(t, styled, "OPTIONS:\n   <--config FILE, -c FILE>  load FILE\n   <--help, -h>\n   - not a flag\n   -- neither\n")
+}
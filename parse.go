@@ -2,6 +2,8 @@ package cli
 
 import (
 	"flag"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -16,20 +18,23 @@ type iterativeParser interface {
 // combined short options from common arguments that should be left untouched.
 // Pass `shellComplete` to continue parsing options on failure during shell
 // completion when, the user-supplied options may be incomplete.
-func parseIter(set *flag.FlagSet, ip iterativeParser, args []string, shellComplete bool) error {
+//
+// counts is filled with how often each flag is set while parsing.
+func parseIter(set *flag.FlagSet, ip iterativeParser, args []string, shellComplete bool, counts occurrences) error {
 	for {
-		err := set.Parse(args)
+		args = terminateBeforeNegativeNumber(set, args)
+		err := parseCounting(set, args, counts)
 		if !ip.useShortOptionHandling() || err == nil {
 			if shellComplete {
 				return nil
 			}
-			return err
+			return parseError(set, args, err)
 		}
 
 		errStr := err.Error()
 		trimmed := strings.TrimPrefix(errStr, "flag provided but not defined: -")
 		if errStr == trimmed {
-			return err
+			return parseError(set, args, err)
 		}
 
 		// regenerate the initial args with the split short opts
@@ -43,7 +48,7 @@ func parseIter(set *flag.FlagSet, ip iterativeParser, args []string, shellComple
 			// if we can't split, the error was accurate
 			shortOpts := splitShortOptions(set, arg)
 			if len(shortOpts) == 1 {
-				return err
+				return parseError(set, args, err)
 			}
 
 			// swap current argument with the split version
@@ -55,7 +60,7 @@ func parseIter(set *flag.FlagSet, ip iterativeParser, args []string, shellComple
 		// This should be an impossible to reach code path, but in case the arg
 		// splitting failed to happen, this will prevent infinite loops
 		if !argsWereSplit {
-			return err
+			return parseError(set, args, err)
 		}
 
 		// Since custom parsing failed, replace the flag set before retrying
@@ -67,6 +72,228 @@ func parseIter(set *flag.FlagSet, ip iterativeParser, args []string, shellComple
 	}
 }
 
+// occurrences holds how often each flag of a flag set was set while its
+// arguments were parsed, by name. The names of a flag that share its value
+// share a count.
+type occurrences map[string]*int
+
+// count returns how often the flag called name was set
+func (o occurrences) count(name string) int {
+	if n := o[name]; n != nil {
+		return *n
+	}
+	return 0
+}
+
+// occurrenceCounter wraps the value of a flag while arguments are parsed,
+// counting how often its Set method is called and recording the value it
+// failed to set, if any
+type occurrenceCounter struct {
+	flag.Value
+	name    string
+	count   *int
+	invalid **InvalidValueError
+}
+
+// Set counts the occurrence and sets the wrapped value
+func (o *occurrenceCounter) Set(value string) error {
+	*o.count++
+	err := o.Value.Set(value)
+	if err != nil {
+		*o.invalid = &InvalidValueError{Flag: o.name, Value: value, cause: err}
+	}
+	return err
+}
+
+// String returns the representation of the wrapped value
+func (o *occurrenceCounter) String() string {
+	if o.Value == nil {
+		return ""
+	}
+	return o.Value.String()
+}
+
+// IsBoolFlag lets the flag be given without a value if the wrapped value does
+func (o *occurrenceCounter) IsBoolFlag() bool {
+	bf, ok := o.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+// parseCounting parses args into set like set.Parse and replaces the
+// contents of counts with how often each flag was set. The values of the
+// flags are wrapped with an occurrenceCounter only while parsing. A value
+// that cannot be set is returned as an InvalidValueError.
+func parseCounting(set *flag.FlagSet, args []string, counts occurrences) error {
+	for name := range counts {
+		delete(counts, name)
+	}
+
+	var invalid *InvalidValueError
+
+	shared := map[flag.Value]*int{}
+	wrapped := map[*flag.Flag]flag.Value{}
+	set.VisitAll(func(f *flag.Flag) {
+		count := new(int)
+		if reflect.TypeOf(f.Value).Comparable() {
+			if n, ok := shared[f.Value]; ok {
+				count = n
+			} else {
+				shared[f.Value] = count
+			}
+		}
+		counts[f.Name] = count
+		wrapped[f] = f.Value
+		f.Value = &occurrenceCounter{Value: f.Value, name: f.Name, count: count, invalid: &invalid}
+	})
+	defer func() {
+		for f, value := range wrapped {
+			f.Value = value
+		}
+	}()
+
+	err := set.Parse(args)
+	if err != nil && invalid != nil {
+		invalid.err = err
+		return invalid
+	}
+	return err
+}
+
+// parseError replaces the error returned by the flag package with one of
+// UnknownFlagError and MissingValueError, so that callers can tell them
+// apart, which the flag package only does in its messages. An undefined
+// flag is named as it was given on the command line. Other errors, including
+// the InvalidValueError of parseCounting, are returned unchanged.
+func parseError(set *flag.FlagSet, args []string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*InvalidValueError); ok {
+		return err
+	}
+
+	errStr := err.Error()
+	if name := strings.TrimPrefix(errStr, "flag needs an argument: -"); name != errStr {
+		return &MissingValueError{Flag: name}
+	}
+
+	name := strings.TrimPrefix(errStr, "flag provided but not defined: -")
+	if errStr == name {
+		return err
+	}
+
+	token := prefixFor(name) + name
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if flagArg := strings.SplitN(arg, "=", 2)[0]; strings.TrimLeft(flagArg, "-") == name {
+			token = flagArg
+			break
+		}
+	}
+
+	return &UnknownFlagError{Flag: token, Command: set.Name()}
+}
+
+// splitUnknownFlags separates the arguments that are flags not defined in
+// flags, together with their values, from the others. The argument after
+// an unknown flag given without "=" is taken as its value unless it looks
+// like a flag itself, as there is no way to tell whether the flag takes a
+// value. Everything after "--" is left to the known arguments.
+func splitUnknownFlags(flags []Flag, args []string) (known []string, unknown []string) {
+	known = []string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			known = append(known, args[i:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' || isNegativeNumber(arg) {
+			known = append(known, arg)
+			continue
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		hasValue := strings.Contains(name, "=")
+		name = strings.SplitN(name, "=", 2)[0]
+		nextIsValue := !hasValue && i+1 < len(args)
+
+		var f Flag
+		for _, fl := range flags {
+			for _, n := range fl.Names() {
+				if n == name {
+					f = fl
+				}
+			}
+		}
+
+		if f != nil {
+			known = append(known, arg)
+			if df, ok := f.(DocGenerationFlag); ok && df.TakesValue() && nextIsValue {
+				i++
+				known = append(known, args[i])
+			}
+			continue
+		}
+
+		unknown = append(unknown, arg)
+		if nextIsValue && !strings.HasPrefix(args[i+1], "-") {
+			i++
+			unknown = append(unknown, args[i])
+		}
+	}
+
+	return known, unknown
+}
+
+// terminateBeforeNegativeNumber inserts "--" before the first argument that
+// is a negative number, e.g. -5 or -0.5, where the flag package would take it
+// for a flag, so that it is parsed as the first positional argument instead.
+// A negative number given as the value of a flag, as in "--offset -5", or
+// matching the name of a defined flag is left alone.
+func terminateBeforeNegativeNumber(set *flag.FlagSet, args []string) []string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+			return args
+		}
+
+		if isNegativeNumber(arg) && set.Lookup(arg[1:]) == nil {
+			return append(args[:i:i], append([]string{"--"}, args[i:]...)...)
+		}
+
+		name := strings.TrimPrefix(arg[1:], "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+
+		f := set.Lookup(name)
+		if f == nil {
+			return args
+		}
+		if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !bf.IsBoolFlag() {
+			// skip the value of the flag
+			i++
+		}
+	}
+
+	return args
+}
+
+func isNegativeNumber(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' {
+		return false
+	}
+	if c := arg[1]; c < '0' || c > '9' {
+		if c != '.' || len(arg) < 3 || arg[2] < '0' || arg[2] > '9' {
+			return false
+		}
+	}
+	_, err := strconv.ParseFloat(arg, 64)
+	return err == nil
+}
+
 func splitShortOptions(set *flag.FlagSet, arg string) []string {
 	shortFlagsExist := func(s string) bool {
 		for _, c := range s[1:] {
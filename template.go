@@ -22,7 +22,12 @@ AUTHOR{{with $length := len .Authors}}{{if ne 1 $length}}S{{end}}{{end}}:
 COMMANDS:{{range .VisibleCategories}}{{if .Name}}
    {{.Name}}:{{range .VisibleCommands}}
      {{join .Names ", "}}{{"\t"}}{{.Usage}}{{end}}{{else}}{{range .VisibleCommands}}
-   {{join .Names ", "}}{{"\t"}}{{.Usage}}{{end}}{{end}}{{end}}{{end}}{{if .VisibleFlags}}
+   {{join .Names ", "}}{{"\t"}}{{.Usage}}{{end}}{{end}}{{end}}{{end}}{{if .VisibleFlagCategories}}
+
+GLOBAL OPTIONS:{{range .VisibleFlagCategories}}{{if .Name}}
+   {{.Name}}:{{range .Flags}}
+     {{.}}{{end}}{{else}}{{range .Flags}}
+   {{.}}{{end}}{{end}}{{end}}{{else if .VisibleFlags}}
 
 GLOBAL OPTIONS:
    {{range $index, $option := .VisibleFlags}}{{if $index}}
@@ -45,7 +50,12 @@ CATEGORY:
    {{.Category}}{{end}}{{if .Description}}
 
 DESCRIPTION:
-   {{.Description | nindent 3 | trim}}{{end}}{{if .VisibleFlags}}
+   {{.Description | nindent 3 | trim}}{{end}}{{if .VisibleFlagCategories}}
+
+OPTIONS:{{range .VisibleFlagCategories}}{{if .Name}}
+   {{.Name}}:{{range .Flags}}
+     {{.}}{{end}}{{else}}{{range .Flags}}
+   {{.}}{{end}}{{end}}{{end}}{{else if .VisibleFlags}}
 
 OPTIONS:
    {{range .VisibleFlags}}{{.}}
@@ -67,7 +77,12 @@ DESCRIPTION:
 COMMANDS:{{range .VisibleCategories}}{{if .Name}}
    {{.Name}}:{{range .VisibleCommands}}
      {{join .Names ", "}}{{"\t"}}{{.Usage}}{{end}}{{else}}{{range .VisibleCommands}}
-   {{join .Names ", "}}{{"\t"}}{{.Usage}}{{end}}{{end}}{{end}}{{if .VisibleFlags}}
+   {{join .Names ", "}}{{"\t"}}{{.Usage}}{{end}}{{end}}{{end}}{{if .VisibleFlagCategories}}
+
+OPTIONS:{{range .VisibleFlagCategories}}{{if .Name}}
+   {{.Name}}:{{range .Flags}}
+     {{.}}{{end}}{{else}}{{range .Flags}}
+   {{.}}{{end}}{{end}}{{end}}{{else if .VisibleFlags}}
 
 OPTIONS:
    {{range .VisibleFlags}}{{.}}
@@ -120,3 +135,33 @@ end
 
 {{ range $v := .Completions }}{{ $v }}
 {{ end }}`
+
+var ZshCompletionTemplate = `#compdef {{ .App.Name }}
+
+{{ range $v := .Functions }}{{ $v }}
+{{ end }}if [ "$funcstack[1]" = "_{{ .App.Name }}" ]; then
+  _{{ .App.Name }} "$@"
+else
+  compdef _{{ .App.Name }} {{ .App.Name }}
+fi
+`
+
+var BashCompletionTemplate = `# {{ .App.Name }} bash shell completion
+
+_{{ .App.Name }}_bash_autocomplete() {
+  if [[ "${COMP_WORDS[0]}" != "source" ]]; then
+    local cur opts
+    COMPREPLY=()
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    if [[ "$cur" == "-"* ]]; then
+      opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} ${cur} --generate-bash-completion )
+    else
+      opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} --generate-bash-completion )
+    fi
+    COMPREPLY=( $(compgen -W "${opts}" -- ${cur}) )
+    return 0
+  fi
+}
+
+complete -o bashdefault -o default -o nospace -F _{{ .App.Name }}_bash_autocomplete {{ .App.Name }}
+`
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// ToZshCompletion creates a zsh completion string for the `*App`
+// The function errors if either parsing or writing of the string fails.
+func (a *App) ToZshCompletion() (string, error) {
+	var w bytes.Buffer
+	if err := a.writeZshCompletionTemplate(&w); err != nil {
+		return "", err
+	}
+	return w.String(), nil
+}
+
+type zshCompletionTemplate struct {
+	App       *App
+	Functions []string
+}
+
+func (a *App) writeZshCompletionTemplate(w io.Writer) error {
+	const name = "cli"
+	t, err := template.New(name).Parse(ZshCompletionTemplate)
+	if err != nil {
+		return err
+	}
+
+	// Add global flags
+	flags := a.VisibleFlags()
+
+	// Add help flag
+	if !a.HideHelp {
+		flags = append(flags, a.helpFlag())
+	}
+
+	// Add version flag
+	if !a.HideVersion {
+		flags = append(flags, a.versionFlag())
+	}
+
+	return t.ExecuteTemplate(w, name, &zshCompletionTemplate{
+		App:       a,
+		Functions: a.prepareZshFunctions("_"+a.Name, flags, a.VisibleCommands()),
+	})
+}
+
+// prepareZshFunctions returns the completion function called funcName for
+// the given flags and commands, followed by the completion functions of the
+// commands themselves.
+func (a *App) prepareZshFunctions(funcName string, flags []Flag, commands []*Command) []string {
+	specs := zshFlagSpecs(flags)
+
+	var cmds []*Command
+	for _, command := range commands {
+		if !command.Hidden {
+			cmds = append(cmds, command)
+		}
+	}
+	if len(cmds) > 0 {
+		specs = append(specs, "'1: :->cmds'", "'*::arg:->args'")
+	}
+
+	var fn strings.Builder
+	fn.WriteString(fmt.Sprintf("%s() {\n", funcName))
+
+	switch {
+	case len(cmds) > 0:
+		fn.WriteString("  local line state\n\n")
+		fn.WriteString("  _arguments -C \\\n    ")
+	case len(specs) > 0:
+		fn.WriteString("  _arguments \\\n    ")
+	default:
+		fn.WriteString("  _files")
+	}
+	fn.WriteString(strings.Join(specs, " \\\n    "))
+	fn.WriteString("\n")
+
+	if len(cmds) > 0 {
+		fn.WriteString("\n  case $state in\n")
+		fn.WriteString("    cmds)\n")
+		fn.WriteString("      local -a commands\n")
+		fn.WriteString("      commands=(\n")
+		for _, command := range cmds {
+			for _, name := range command.Names() {
+				entry := zshEscapeColons(name)
+				if command.Usage != "" {
+					entry += ":" + zshEscapeColons(command.Usage)
+				}
+				fn.WriteString(fmt.Sprintf("        '%s'\n", zshEscape(entry)))
+			}
+		}
+		fn.WriteString("      )\n")
+		fn.WriteString("      _describe 'command' commands\n")
+		fn.WriteString("      ;;\n")
+		fn.WriteString("    args)\n")
+		fn.WriteString("      case $line[1] in\n")
+		for _, command := range cmds {
+			fn.WriteString(fmt.Sprintf("        %s)\n", strings.Join(command.Names(), "|")))
+			fn.WriteString(fmt.Sprintf("          %s_%s\n", funcName, command.Name))
+			fn.WriteString("          ;;\n")
+		}
+		fn.WriteString("      esac\n")
+		fn.WriteString("      ;;\n")
+		fn.WriteString("  esac\n")
+	}
+	fn.WriteString("}\n")
+
+	functions := []string{fn.String()}
+	for _, command := range cmds {
+		commandFlags := command.VisibleFlags()
+		if !command.HideHelp && !command.HideHelpFlag {
+			commandFlags = append(commandFlags, a.helpFlag())
+		}
+
+		// recursively iterate subcommands
+		functions = append(functions, a.prepareZshFunctions(
+			funcName+"_"+command.Name, commandFlags, command.Subcommands,
+		)...)
+	}
+
+	return functions
+}
+
+// zshFlagSpecs returns the _arguments specs of the given flags, using each
+// flag's Usage as its description.
+func zshFlagSpecs(flags []Flag) []string {
+	specs := []string{}
+	for _, f := range flags {
+		flag, ok := f.(DocGenerationFlag)
+		if !ok {
+			continue
+		}
+		if vf, ok := f.(VisibleFlag); ok && !vf.IsVisible() {
+			continue
+		}
+
+		names := []string{}
+		for _, name := range flag.Names() {
+			name = strings.TrimSpace(name)
+			names = append(names, prefixFor(name)+name)
+		}
+
+		var spec strings.Builder
+		if len(names) == 1 {
+			spec.WriteString("'" + names[0])
+		} else {
+			spec.WriteString(fmt.Sprintf("'(%s)'{%s}'",
+				strings.Join(names, " "), strings.Join(names, ",")))
+		}
+
+		spec.WriteString("[" + zshEscape(zshEscapeBrackets(flag.GetUsage())) + "]")
+
+		if flag.TakesValue() {
+			spec.WriteString(":" + zshEscape(zshEscapeColons(flag.Names()[0])) + ":")
+			if zshTakesFile(f) {
+				spec.WriteString("_files")
+			}
+		}
+		spec.WriteString("'")
+
+		specs = append(specs, spec.String())
+	}
+
+	return specs
+}
+
+func zshTakesFile(flag Flag) bool {
+	switch f := flag.(type) {
+	case *GenericFlag:
+		return f.TakesFile
+	case *StringFlag:
+		return f.TakesFile
+	case *StringSliceFlag:
+		return f.TakesFile
+	case *PathFlag:
+		return f.TakesFile
+	}
+	return false
+}
+
+// zshEscape escapes input for use inside a single quoted zsh string
+func zshEscape(input string) string {
+	return strings.Replace(input, `'`, `'\''`, -1)
+}
+
+func zshEscapeBrackets(input string) string {
+	return strings.NewReplacer(`[`, `\[`, `]`, `\]`).Replace(input)
+}
+
+func zshEscapeColons(input string) string {
+	return strings.Replace(input, ":", `\:`, -1)
+}
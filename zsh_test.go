@@ -0,0 +1,21 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestZshCompletion(t *testing.T) {
+	// Given
+	app := testApp()
+	app.Flags = append(app.Flags, &PathFlag{
+		Name:      "logfile",
+		TakesFile: true,
+	})
+
+	// When
+	res, err := app.ToZshCompletion()
+
+	// Then
+	expect(t, err, nil)
+	expectFileContent(t, "testdata/expected-zsh-full.zsh", res)
+}